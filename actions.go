@@ -0,0 +1,968 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m model) runCommand(input string) (model, tea.Cmd) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(input, "\\"), " ")
+	arg = strings.TrimSpace(arg)
+	m.input.SetValue("")
+
+	switch name {
+	case "q":
+		return m, m.quit()
+
+	case "b":
+		m.batchAdd = true
+		m.enterEditMode("")
+
+	case "f":
+		if m.selectedTaskID != 0 && !m.taskGone() {
+			m.itemFilter = (m.itemFilter + 1) % 3
+			m.reloadItems()
+		}
+
+	case "r":
+		m.restartItem()
+
+	case "d":
+		m.deleteSelected()
+
+	case "i":
+		m.captureToInbox(arg)
+
+	case "share":
+		m.copySnippet()
+
+	case "copy":
+		m.copyFrom(arg)
+
+	case "codes":
+		m.copyCodes(arg)
+
+	case "merge":
+		m.mergeMarked()
+
+	case "shared":
+		m.toggleShared(arg)
+
+	case "due":
+		m.setDue(arg)
+
+	case "goal":
+		m.setGoal(arg)
+
+	case "carry":
+		m.carryOver(arg)
+
+	case "load":
+		m.loadChecklist(arg)
+
+	case "reset":
+		m.confirmReset()
+
+	case "sort":
+		by, desc, ok := m.taskSort, !m.sortDesc, true
+		if arg != "" {
+			by, desc, ok = parseTaskSort(arg)
+		}
+		if !ok {
+			m.setStatus("Sort by one of: none, code, title, status, progress, time, due, activity (add desc to reverse)")
+			break
+		}
+		m.taskSort, m.sortDesc = by, desc
+		m.store.SetSetting("task_sort", formatTaskSort(by, desc))
+		m.reloadTasks()
+
+	case "skip":
+		if it := m.currentItem(); it != nil && !m.taskGone() {
+			toggleSkip(it, time.Now())
+			m.store.SaveItemStatus(*it)
+			m.store.UpdateTaskStatus(m.selectedTaskID)
+			m.reloadItems()
+		}
+
+	case "board":
+		if m.selectedTaskID != 0 {
+			m.board = !m.board
+		}
+
+	case "group":
+		cur, _ := m.currentTask()
+		m.grouped = !m.grouped
+		m.store.SetSetting("group_by_status", strconv.FormatBool(m.grouped))
+		m.reloadTasks()
+		for i, t := range m.tasks {
+			if t.ID == cur.ID && m.selectedTaskID == 0 {
+				m.cursor = i
+			}
+		}
+
+	case "est":
+		m.setEstimate(arg)
+
+	case "desc":
+		m.setDescription(arg)
+
+	case "note":
+		if it := m.currentItem(); it != nil && !m.taskGone() {
+			m.store.SetItemNote(it.ID, arg)
+			m.reloadItems()
+			m.setStatus("Note saved")
+		}
+
+	case "ref":
+		m.setRef(arg)
+
+	case "pomo":
+		m.startPomodoro(arg)
+
+	case "spent":
+		m.setSpent(arg)
+
+	case "split":
+		m.startSplit(arg)
+
+	case "fixstatus":
+		n := m.store.FixTaskStatuses()
+		m.reloadTasks()
+		m.setStatus(fmt.Sprintf("Fixed %d task statuses", n))
+
+	case "orphans":
+		m.repairOrphans(arg)
+
+	case "saveas":
+		if arg == "" {
+			m.setStatus("Usage: \\saveas <path>")
+			break
+		}
+		if err := m.store.SaveAs(arg); err != nil {
+			m.setStatus("Save failed: " + err.Error())
+			break
+		}
+		m.setStatus("Saved a copy to " + arg)
+
+	case "csv":
+		if arg == "" {
+			m.setStatus("Usage: \\csv <path>")
+			break
+		}
+		data, err := m.store.DailyCSV()
+		if err == nil {
+			err = os.WriteFile(arg, data, 0o644)
+		}
+		if err != nil {
+			m.setStatus("Export failed: " + err.Error())
+			break
+		}
+		m.setStatus("Exported daily totals to " + arg)
+
+	case "archive":
+		if m.selectedTaskID == 0 {
+			m.archiveDone()
+		}
+
+	case "unarchive":
+		if arg == "" {
+			m.setStatus("Usage: \\unarchive <code>")
+			break
+		}
+		if err := m.store.UnarchiveTask(arg); err != nil {
+			m.setStatus(err.Error())
+			break
+		}
+		m.reloadTasks()
+		m.setStatus("Unarchived " + arg)
+
+	case "trash":
+		m.openTrash()
+
+	case "heatmap":
+		m.openHeatmap(arg)
+
+	case "stats":
+		m.openStats()
+
+	case "report":
+		m.openReport(arg)
+
+	case "compact":
+		m.compact = true
+
+	case "pause":
+		m.togglePause(time.Now())
+
+	case "tpl":
+		m.runTemplate(arg)
+
+	case "set":
+		key, value, _ := strings.Cut(arg, " ")
+		if key == "" {
+			m.setStatus("Usage: \\set <key> <value>")
+			break
+		}
+		m.store.SetSetting(key, strings.TrimSpace(value))
+		m.cfg = loadConfig(m.store)
+		if m.selectedTaskID != 0 {
+			m.reloadItems()
+		}
+		m.setStatus("Set " + key)
+
+	default:
+		m.setStatus("Unknown command: " + input)
+	}
+	return m, nil
+}
+
+// confirmation holds a y/n question. preview lists the rows the action
+// will change, of which the first previewSample are shown.
+type confirmation struct {
+	prompt  string
+	preview []string
+	action  func(*model)
+}
+
+const previewSample = 5
+
+func (c confirmation) view() string {
+	var b strings.Builder
+	for i, line := range c.preview {
+		if i == previewSample {
+			fmt.Fprintf(&b, "  … and %d more\n", len(c.preview)-previewSample)
+			break
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String() + c.prompt
+}
+
+// itemPreview describes items the way the list shows them, for a preview.
+func itemPreview(items []item, now time.Time) []string {
+	lines := []string{}
+	for _, it := range items {
+		lines = append(lines, statusMarker(it.Status)+" "+it.Text+" ("+formatDuration(it.elapsed(now))+")")
+	}
+	return lines
+}
+
+// confirmIfMany runs action at once when preview is within the confirm
+// threshold, and asks first, showing the preview, when it is longer.
+func (m *model) confirmIfMany(preview []string, prompt string, action func(*model)) {
+	if len(preview) <= m.cfg.ConfirmThreshold {
+		action(m)
+		return
+	}
+	m.confirm = &confirmation{
+		prompt:  fmt.Sprintf("%s (%d items)? y/n", prompt, len(preview)),
+		preview: preview,
+		action:  action,
+	}
+}
+
+func (m model) updateConfirm(msg tea.KeyMsg) (model, tea.Cmd) {
+	c := m.confirm
+	m.confirm = nil
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "y", "Y":
+		c.action(&m)
+	default:
+		m.setStatus("Cancelled")
+	}
+	return m, nil
+}
+
+type prompt struct {
+	label  string
+	submit func(m *model, value string, pos int)
+	inline bool
+}
+
+func (m *model) startPrompt(label, value string, submit func(m *model, value string, pos int)) {
+	m.prompt = &prompt{label: label, submit: submit}
+	m.enterEditMode(value)
+}
+
+// enterEditMode loads text into the focused input with the cursor at the
+// end; exitEditMode empties it again. Both take the placeholder for the
+// current mode, so set the mode first.
+func (m *model) enterEditMode(text string) {
+	m.input.Placeholder = m.placeholder()
+	m.input.SetValue(text)
+	m.input.CursorEnd()
+	m.syncFocus()
+}
+
+func (m *model) exitEditMode() {
+	m.input.Placeholder = m.placeholder()
+	m.input.SetValue("")
+	m.syncFocus()
+}
+
+func (m model) updatePrompt(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+
+	case "esc":
+		m.prompt = nil
+		m.exitEditMode()
+		return m, nil
+
+	case "enter":
+		p := m.prompt
+		value, pos := m.input.Value(), m.input.Position()
+		m.prompt = nil
+		m.exitEditMode()
+		p.submit(&m, value, pos)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *model) editSelected() {
+	if t, ok := m.currentTask(); ok {
+		m.startPrompt("Edit the task title", t.Title, func(m *model, value string, _ int) {
+			if value = strings.TrimSpace(value); value == "" {
+				m.setStatus("Title cannot be empty")
+				return
+			}
+			m.store.SetTaskTitle(t.ID, value)
+			m.reloadTasks()
+		})
+		return
+	}
+	cur := m.currentItem()
+	if cur == nil || m.taskGone() {
+		return
+	}
+	id := cur.ID
+	m.startPrompt("Edit the item", cur.Text, func(m *model, value string, _ int) {
+		if value = strings.TrimSpace(value); value == "" {
+			m.setStatus("Item text cannot be empty")
+			return
+		}
+		m.store.UpdateItemText(id, value)
+		m.store.UpdateTaskStatus(m.selectedTaskID)
+		m.reloadItems()
+	})
+}
+
+// renameSelected edits the selected row in place, a task as "CODE: title"
+// so the code can change too. The board has no rows, so it keeps the
+// prompt below.
+func (m *model) renameSelected() {
+	if t, ok := m.currentTask(); ok {
+		m.startPrompt("Rename the task", t.Code+": "+t.Title, func(m *model, value string, _ int) {
+			if err := m.store.UpdateTask(t.ID, value); err != nil {
+				m.setStatus(err.Error())
+				return
+			}
+			m.reloadTasks()
+		})
+	} else {
+		m.editSelected()
+	}
+	if m.prompt != nil && !m.board {
+		m.prompt.inline = true
+	}
+}
+
+func (m model) inlineEditing(row int) bool {
+	return m.prompt != nil && m.prompt.inline && row == m.cursor
+}
+
+// inlineInput renders the input without its prompt, to sit inside a row.
+func (m model) inlineInput() string {
+	in := m.input
+	in.Prompt = ""
+	return in.View()
+}
+
+func (m *model) startSplit(delim string) {
+	cur := m.currentItem()
+	if cur == nil || m.taskGone() {
+		return
+	}
+	it := *cur
+	if delim != "" {
+		at := strings.Index(it.Text, delim)
+		if at < 0 {
+			m.setStatus(fmt.Sprintf("%q not found in item", delim))
+			return
+		}
+		m.splitItem(it, it.Text[:at], it.Text[at+len(delim):])
+		return
+	}
+	m.startPrompt("Move the cursor to the split point", it.Text, func(m *model, value string, pos int) {
+		r := []rune(value)
+		m.splitItem(it, string(r[:pos]), string(r[pos:]))
+	})
+}
+
+func (m *model) splitItem(it item, first, second string) {
+	first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+	if first == "" || second == "" {
+		m.setStatus("Nothing to split")
+		return
+	}
+	now := time.Now()
+	it.Text = first
+	it.Status = NotStarted
+	it.StartedAt = now
+	it.CheckedAt = nil
+	it.FrozenDuration = 0
+	it.ClockedOut = false
+	m.store.UpdateItemText(it.ID, first)
+	m.store.SaveItemStatus(it)
+	m.store.InsertItemAfter(it, item{Text: second, Status: NotStarted, CreatedAt: now})
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.reloadItems()
+	m.setStatus("Split into two items")
+}
+
+func (m *model) toggleMark() {
+	it := m.currentItem()
+	if it == nil {
+		return
+	}
+	if m.marked == nil {
+		m.marked = map[int64]bool{}
+	}
+	id := it.ID
+	if m.marked[id] {
+		delete(m.marked, id)
+	} else {
+		m.marked[id] = true
+	}
+}
+
+func (m model) markedItems() []item {
+	items := []item{}
+	for _, it := range m.items {
+		if m.marked[it.ID] {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+func (m *model) mergeMarked() {
+	if m.selectedTaskID == 0 || m.taskGone() {
+		return
+	}
+	items := m.markedItems()
+	if len(items) < 2 {
+		m.setStatus("Mark at least two items with ctrl+x to merge")
+		return
+	}
+	m.confirmIfMany(itemPreview(items, time.Now()), "Merge", func(m *model) {
+		merged := mergeItems(items, time.Now())
+		for _, it := range items {
+			m.store.DeleteItem(it.ID)
+		}
+		m.store.SaveItem(merged)
+		m.store.UpdateTaskStatus(m.selectedTaskID)
+		m.marked = nil
+		m.reloadItems()
+		m.setStatus(fmt.Sprintf("Merged %d items", len(items)))
+	})
+}
+
+func (m *model) copyFrom(code string) {
+	if m.selectedTaskID == 0 || m.taskGone() {
+		return
+	}
+	if code == "" {
+		m.setStatus("Usage: \\copy <task code>")
+		return
+	}
+	src, err := m.store.ResolveTask(command{Task: code})
+	if err != nil {
+		m.setStatus(code + ": " + err.Error())
+		return
+	}
+	if src.ID == m.selectedTaskID {
+		m.setStatus("Cannot copy a task into itself")
+		return
+	}
+	n, err := m.store.CopyItems(src.ID, m.selectedTaskID)
+	if err != nil {
+		m.setStatus("Copy failed: " + err.Error())
+		return
+	}
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.reloadItems()
+	m.setStatus(fmt.Sprintf("Copied %d items from %s", n, src.Code))
+}
+
+func (m *model) setDue(arg string) {
+	taskID := m.selectedTaskID
+	if taskID == 0 {
+		t, ok := m.currentTask()
+		if !ok {
+			return
+		}
+		taskID = t.ID
+	} else if m.taskGone() {
+		return
+	}
+	if arg == "pick" {
+		t, _ := m.taskByID(taskID)
+		m.openDatePicker(t.Due, func(m *model, due *time.Time) { m.applyDue(taskID, due) })
+		return
+	}
+	var due *time.Time
+	if arg != "" {
+		d, err := parseDue(arg, time.Now())
+		if err != nil {
+			m.setStatus(err.Error())
+			return
+		}
+		due = &d
+	}
+	m.applyDue(taskID, due)
+}
+
+func (m *model) applyDue(taskID int64, due *time.Time) {
+	m.store.SetTaskDue(taskID, due)
+	m.reloadTasks()
+	if due == nil {
+		m.setStatus("Due date cleared")
+	} else {
+		m.setStatus("Due " + due.Format("2006-01-02"))
+	}
+}
+
+func (m *model) setDescription(arg string) {
+	t, ok := m.openedTask()
+	if !ok || m.taskGone() {
+		m.setStatus("Open a task to describe it")
+		return
+	}
+	save := func(m *model, value string, _ int) {
+		m.store.SetTaskDescription(t.ID, strings.TrimSpace(value))
+		m.reloadTasks()
+	}
+	if arg != "" {
+		save(m, arg, 0)
+		return
+	}
+	m.startPrompt("Describe the task goal (empty to clear)", t.Description, save)
+}
+
+func (m *model) promptNote(it item) {
+	m.startPrompt("Note on "+it.Text+" (enter to skip)", "", func(m *model, value string, _ int) {
+		if value = strings.TrimSpace(value); value == "" {
+			return
+		}
+		m.store.SetItemNote(it.ID, value)
+		if m.selectedTaskID == it.TaskID {
+			m.reloadItems()
+		}
+		m.setStatus("Note saved")
+	})
+}
+
+func (m *model) setEstimate(arg string) {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	var d time.Duration
+	if arg != "" {
+		var err error
+		if d, err = parseAmount(arg); err != nil {
+			m.setStatus(err.Error())
+			return
+		}
+	}
+	i.Estimate = d
+	m.store.SetItemEstimate(i.ID, d)
+	delete(m.alerted, i.ID)
+	if d == 0 {
+		m.setStatus("Estimate cleared")
+	} else {
+		m.setStatus("Estimate set to " + formatDuration(d))
+	}
+}
+
+func (m *model) setSpent(arg string) {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	if arg == "" {
+		m.setStatus("Usage: \\spent <duration>")
+		return
+	}
+	d, err := parseAmount(arg)
+	if err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+	logSpent(i, d, time.Now())
+	m.store.SaveItemStatus(*i)
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.reloadItems()
+	m.setStatus("Spent " + formatDuration(d) + " on " + i.Text)
+}
+
+func (m *model) checkOverruns(now time.Time) tea.Cmd {
+	if !m.cfg.NotifyOverrun {
+		return nil
+	}
+	if m.alerted == nil {
+		m.alerted = map[int64]bool{}
+	}
+	var over []item
+	for _, it := range m.store.RunningItems() {
+		if it.Estimate > 0 && !m.alerted[it.ID] && it.elapsed(now) > it.Estimate {
+			m.alerted[it.ID] = true
+			over = append(over, it)
+		}
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	m.setStatus("Over estimate: " + over[0].Text)
+	return bell
+}
+
+func bell() tea.Msg {
+	fmt.Fprint(os.Stderr, "\a")
+	return nil
+}
+
+func (m *model) restartItem() {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	i.Status = Started
+	i.StartedAt = time.Now()
+	i.CheckedAt = nil
+	i.FrozenDuration = 0
+	i.ClockedOut = false
+	m.store.SaveItemStatus(*i)
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+}
+
+func (m *model) togglePriority() {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	i.Priority = !i.Priority
+	m.store.SetItemPriority(i.ID, i.Priority)
+	if m.cfg.PriorityFirst {
+		id := i.ID
+		m.reloadItems()
+		for n, it := range m.items {
+			if it.ID == id {
+				m.cursor = n
+			}
+		}
+	}
+}
+
+func (m *model) reopenLastDone() {
+	if m.selectedTaskID == 0 || m.taskGone() {
+		return
+	}
+	var last *item
+	items := m.store.Items(m.selectedTaskID)
+	for n := range items {
+		it := &items[n]
+		if it.Status == Done && it.CheckedAt != nil && (last == nil || it.CheckedAt.After(*last.CheckedAt)) {
+			last = it
+		}
+	}
+	if last == nil {
+		m.setStatus("No completed item to reopen")
+		return
+	}
+	reopenItem(last, time.Now())
+	m.store.SaveItemStatus(*last)
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.reloadItems()
+	for n, it := range m.items {
+		if it.ID == last.ID {
+			m.cursor = n
+		}
+	}
+	m.setStatus("Reopened " + last.Text)
+}
+
+// cycleTaskStatus steps the task under the cursor through not started,
+// started and done by hand, for tasks tracked without items.
+func (m *model) cycleTaskStatus() {
+	t, ok := m.currentTask()
+	if !ok {
+		return
+	}
+	next := map[itemStatus]itemStatus{NotStarted: Started, Started: Done, Done: NotStarted, Skipped: NotStarted}[t.Status]
+	m.store.SetTaskStatus(t.ID, next)
+	m.reloadTasks()
+	for i, rt := range m.tasks {
+		if rt.ID == t.ID {
+			m.cursor = i
+		}
+	}
+	m.setStatus(fmt.Sprintf("Set %s to %s by hand, item changes will recompute it", t.Code, statusMarker(next)))
+}
+
+func (m *model) toggleClock() {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	toggleClock(i, time.Now())
+	m.store.SaveItemStatus(*i)
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	if i.ClockedOut {
+		m.setStatus("Clocked out at " + formatDuration(i.FrozenDuration))
+	} else {
+		m.setStatus("Clocked in")
+	}
+}
+
+// deleteSelected moves the selection to the trash. With quick_delete set
+// large tasks go without a y/n prompt, so \trash is the only way back.
+func (m *model) deleteSelected() {
+	if t, ok := m.currentTask(); ok {
+		preview := itemPreview(m.store.Items(t.ID), time.Now())
+		if m.cfg.QuickDelete {
+			preview = nil
+		}
+		m.confirmIfMany(preview, "Delete "+t.Code, func(m *model) {
+			if err := m.store.DeleteTask(t.ID); err != nil {
+				m.setStatus("Delete failed: " + err.Error())
+				return
+			}
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			m.reloadTasks()
+			m.setStatus("Deleted " + t.Code + ", \\trash to restore")
+		})
+	} else if it := m.currentItem(); it != nil && !m.taskGone() {
+		if err := m.store.DeleteItem(it.ID); err != nil {
+			m.setStatus("Delete failed: " + err.Error())
+			return
+		}
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.reloadItems()
+		m.store.UpdateTaskStatus(m.selectedTaskID)
+	}
+}
+
+// stepTask opens the task delta places away from the open one in list
+// order, wrapping at the ends when task_wrap is set.
+func (m *model) stepTask(delta int) {
+	at := -1
+	for i, t := range m.tasks {
+		if t.ID == m.selectedTaskID {
+			at = i
+		}
+	}
+	if at < 0 {
+		return
+	}
+	next := at + delta
+	if m.cfg.TaskWrap {
+		next = (next + len(m.tasks)) % len(m.tasks)
+	}
+	if next < 0 || next >= len(m.tasks) || next == at {
+		return
+	}
+	m.openTask(m.tasks[next].ID)
+}
+
+const jumpTimeout = 800 * time.Millisecond
+
+type jumpResetMsg struct {
+	seq int
+}
+
+func (m *model) jumpDigit(d rune) tea.Cmd {
+	n := len(m.tasks)
+	if m.selectedTaskID != 0 {
+		n = len(m.items)
+	}
+	buf := m.jumpBuf + string(d)
+	target, _ := strconv.Atoi(buf)
+	if target < 1 || target > n {
+		buf = string(d)
+		target, _ = strconv.Atoi(buf)
+	}
+	m.jumpBuf = buf
+	if target >= 1 && target <= n {
+		m.cursor = target - 1
+	}
+
+	m.jumpSeq++
+	seq := m.jumpSeq
+	return tea.Tick(jumpTimeout, func(time.Time) tea.Msg {
+		return jumpResetMsg{seq: seq}
+	})
+}
+
+// nextActionable picks the first not-started item to work on: the open task
+// comes first, then tasks already in progress, then the rest in list order.
+// Within a task, high-priority items win over position.
+func nextActionable(tasks []task, openID int64, items map[int64][]item) (item, bool) {
+	order := append([]task(nil), tasks...)
+	rank := func(t task) int {
+		switch {
+		case t.ID == openID:
+			return 0
+		case t.Status == Started:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(order, func(i, j int) bool { return rank(order[i]) < rank(order[j]) })
+
+	for _, t := range order {
+		if next, ok := nextInTask(items[t.ID]); ok {
+			return next, true
+		}
+	}
+	return item{}, false
+}
+
+// nextInTask is the first not-started item, or the first high-priority one
+// if there is any.
+func nextInTask(items []item) (item, bool) {
+	var next *item
+	for n, it := range items {
+		if it.Status == NotStarted && (next == nil || it.Priority && !next.Priority) {
+			next = &items[n]
+		}
+	}
+	if next == nil {
+		return item{}, false
+	}
+	return *next, true
+}
+
+func (m *model) focusNext() {
+	if m.selectedTaskID != 0 && m.taskGone() {
+		return
+	}
+	m.reloadTasks()
+	next, ok := nextActionable(m.tasks, m.selectedTaskID, m.taskItems)
+	if !ok {
+		m.setStatus("All done")
+		return
+	}
+	if next.TaskID != m.selectedTaskID {
+		m.openTask(next.TaskID)
+	}
+	if m.itemFilter == onlyDone {
+		m.itemFilter = showAll
+		m.reloadItems()
+	}
+	for n, it := range m.items {
+		if it.ID == next.ID {
+			m.cursor = n
+		}
+	}
+	m.setStatus("Next: " + next.Text)
+}
+
+// changeItemStatus applies change to an item of the open task and saves it.
+// When that completes the item it asks for a note if note_on_done is set,
+// and with close_on_complete it closes the task once every item is done.
+// It reports whether the task was closed.
+func (m *model) changeItemStatus(i *item, change func(*item, time.Time)) bool {
+	wasDone, wasItemDone := m.store.AllDone(m.selectedTaskID), i.Status == Done
+	change(i, time.Now())
+	m.store.SaveItemStatus(*i)
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	if i.Status != Done || wasItemDone {
+		return false
+	}
+	if m.cfg.NoteOnDone {
+		m.promptNote(*i)
+	}
+	if m.cfg.CloseOnComplete && !wasDone && m.store.AllDone(m.selectedTaskID) {
+		m.closeTask()
+		t, _ := m.currentTask()
+		m.setStatus("Completed " + t.Code)
+		return true
+	}
+	return false
+}
+
+// completeAndAdvance marks the selected item Done and moves the cursor to
+// the next not-started item in the task, wrapping at the end.
+func (m *model) completeAndAdvance() {
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		return
+	}
+	id := i.ID
+	if !i.Status.closed() {
+		if m.changeItemStatus(i, completeItem) {
+			return
+		}
+		m.reloadItems()
+	}
+	from := m.cursor
+	for n, it := range m.items {
+		if it.ID == id {
+			from = n + 1
+		}
+	}
+	for k := range m.items {
+		n := (from + k) % len(m.items)
+		if m.items[n].Status == NotStarted {
+			m.cursor = n
+			m.setStatus("Next: " + m.items[n].Text)
+			return
+		}
+	}
+	m.setStatus("No unfinished items left")
+}
+
+func (m *model) captureToInbox(text string) {
+	inboxID := m.store.InboxTaskID()
+	if inboxID == 0 {
+		m.setStatus("Could not open the inbox")
+		return
+	}
+	if text == "" {
+		m.openTask(inboxID)
+		return
+	}
+	m.store.SaveItem(item{
+		TaskID:    inboxID,
+		Text:      text,
+		Status:    NotStarted,
+		CreatedAt: time.Now(),
+	})
+	m.store.UpdateTaskStatus(inboxID)
+	if m.selectedTaskID == inboxID {
+		m.reloadItems()
+	} else if m.selectedTaskID == 0 {
+		m.reloadTasks()
+	}
+	m.setStatus("Added to Inbox: " + text)
+	m.warnItemCap(inboxID)
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompletionPathsHonourSettings(t *testing.T) {
+	paths := []struct {
+		name     string
+		complete func(m model) model
+	}{
+		{"space", func(m model) model { return press(m, " ", " ") }},
+		{"complete and advance", func(m model) model { m.completeAndAdvance(); return m }},
+		{"board shift", func(m model) model {
+			m.shiftCard(m.currentItem(), true)
+			m.shiftCard(m.currentItem(), true)
+			return m
+		}},
+		{"nav x", func(m model) model { m.setNavMode(true); return press(m, "x") }},
+	}
+	settings := []struct {
+		name                 string
+		noteOnDone, closeAll bool
+	}{
+		{"neither", false, false},
+		{"note_on_done", true, false},
+		{"close_on_complete", false, true},
+		{"both", true, true},
+	}
+	for _, p := range paths {
+		for _, s := range settings {
+			m := newTestModel(t)
+			m.cfg.NoteOnDone, m.cfg.CloseOnComplete = s.noteOnDone, s.closeAll
+			id, err := m.store.CreateTask("Complete")
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.store.SaveItem(item{TaskID: id, Text: "only"})
+			m.reloadTasks()
+			m.openTask(id)
+			m = p.complete(m)
+			if got := m.store.Items(id)[0].Status; got != Done {
+				t.Errorf("%s/%s: item status = %v, want done", p.name, s.name, got)
+				continue
+			}
+			if prompted := m.prompt != nil; prompted != s.noteOnDone {
+				t.Errorf("%s/%s: note prompt shown = %v, want %v", p.name, s.name, prompted, s.noteOnDone)
+			}
+			if closed := m.selectedTaskID == 0; closed != s.closeAll {
+				t.Errorf("%s/%s: task closed = %v, want %v", p.name, s.name, closed, s.closeAll)
+			}
+		}
+	}
+}
+
+func TestDigitsTypeWhileInputHasFocus(t *testing.T) {
+	alt3 := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}, Alt: true}
+	tests := []struct {
+		name       string
+		nav        bool
+		keys       []any
+		wantInput  string
+		wantCursor int
+	}{
+		{"digit is typed", false, []any{"3 eggs"}, "3 eggs", 0},
+		{"alt+digit jumps", false, []any{alt3}, "", 2},
+		{"alt+digit jumps over text", false, []any{"eggs", alt3}, "eggs", 2},
+		{"digit jumps in nav mode", true, []any{"3"}, "", 2},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		id, err := m.store.CreateTask("Shopping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, text := range []string{"milk", "bread", "jam"} {
+			m.store.SaveItem(item{TaskID: id, Text: text, Position: i})
+		}
+		m.reloadTasks()
+		m.openTask(id)
+		m.numbered = true
+		m.setNavMode(tt.nav)
+		m = press(m, tt.keys...)
+		if got := m.input.Value(); got != tt.wantInput {
+			t.Errorf("%s: input = %q, want %q", tt.name, got, tt.wantInput)
+		}
+		if m.cursor != tt.wantCursor {
+			t.Errorf("%s: cursor = %d, want %d", tt.name, m.cursor, tt.wantCursor)
+		}
+	}
+}
+
+func TestBracketsTypeWhileInputHasFocus(t *testing.T) {
+	altNext := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}, Alt: true}
+	tests := []struct {
+		name      string
+		nav       bool
+		keys      []any
+		wantInput string
+		wantNext  bool
+	}{
+		{"bracket is typed", false, []any{"[WIP] x"}, "[WIP] x", false},
+		{"alt+bracket steps", false, []any{altNext}, "", true},
+		{"bracket steps in nav mode", true, []any{"]"}, "", true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		first, err := m.store.CreateTask("First")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.store.CreateTask("Second"); err != nil {
+			t.Fatal(err)
+		}
+		m.reloadTasks()
+		m.openTask(first)
+		m.setNavMode(tt.nav)
+		m = press(m, tt.keys...)
+		if got := m.input.Value(); got != tt.wantInput {
+			t.Errorf("%s: input = %q, want %q", tt.name, got, tt.wantInput)
+		}
+		if moved := m.selectedTaskID != first; moved != tt.wantNext {
+			t.Errorf("%s: stepped to another task = %v, want %v", tt.name, moved, tt.wantNext)
+		}
+	}
+}
+
+func TestCommandsRunOnEnter(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []any
+		wantTasks int
+		wantInput string
+	}{
+		{"typed command waits for enter", []any{"\\d"}, 1, "\\d"},
+		{"enter runs the command", []any{"\\d", tea.KeyEnter}, 0, ""},
+		{"text after the command is its argument", []any{"\\d now", tea.KeyEnter}, 0, ""},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.cfg.ConfirmThreshold = 0
+		if _, err := m.store.CreateTask("Doomed"); err != nil {
+			t.Fatal(err)
+		}
+		m.reloadTasks()
+		m = press(m, tt.keys...)
+		if got := len(m.store.Tasks()); got != tt.wantTasks {
+			t.Errorf("%s: %d tasks, want %d", tt.name, got, tt.wantTasks)
+		}
+		if got := m.input.Value(); got != tt.wantInput {
+			t.Errorf("%s: input = %q, want %q", tt.name, got, tt.wantInput)
+		}
+	}
+}
+
+func TestInboxCaptureKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		inTask    bool
+		text      string
+		wantItems []string
+		wantOpen  bool
+	}{
+		{"captures from the task list", false, "buy milk", []string{"buy milk"}, false},
+		{"captures from inside a task", true, "  call Bob ", []string{"call Bob"}, false},
+		{"empty input opens the inbox", false, "", nil, true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		other, err := m.store.CreateTask("Other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.reloadTasks()
+		if tt.inTask {
+			m.openTask(other)
+		}
+		m.input.SetValue(tt.text)
+		m = press(m, tea.KeyCtrlB)
+		inbox := m.store.InboxTaskID()
+		var got []string
+		for _, it := range m.store.Items(inbox) {
+			got = append(got, it.Text)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.wantItems, ",") {
+			t.Errorf("%s: inbox items = %q, want %q", tt.name, got, tt.wantItems)
+		}
+		if open := m.selectedTaskID == inbox; open != tt.wantOpen {
+			t.Errorf("%s: inbox open = %v, want %v", tt.name, open, tt.wantOpen)
+		}
+		if got := len(m.store.Items(other)); got != 0 {
+			t.Errorf("%s: %d items landed in the open task", tt.name, got)
+		}
+		if m.input.Value() != "" {
+			t.Errorf("%s: input not cleared: %q", tt.name, m.input.Value())
+		}
+	}
+}
+
+func TestInboxIsReusedFromTrashAndArchive(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup string // run against the old inbox, "" for none
+	}{
+		{"no inbox yet", ""},
+		{"live inbox", "UPDATE tasks SET title = title WHERE id = ?"},
+		{"inbox in the trash", "UPDATE tasks SET deleted_at = '2026-01-01T00:00:00Z' WHERE id = ?"},
+		{"archived inbox", "UPDATE tasks SET archived_at = '2026-01-01T00:00:00Z' WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		m := newModel(newStore(db))
+		var old int64
+		if tt.setup != "" {
+			old = inboxTaskID(db)
+			execDB(db, tt.setup, old)
+			m.reloadTasks()
+		}
+		m.input.SetValue("captured")
+		m = press(m, tea.KeyCtrlB)
+		var n int
+		db.QueryRow("SELECT COUNT(*) FROM tasks WHERE code = ?", inboxCode).Scan(&n)
+		if n != 1 {
+			t.Errorf("%s: %d tasks coded %s, want 1", tt.name, n, inboxCode)
+		}
+		inbox := inboxTaskID(db)
+		if old != 0 && inbox != old {
+			t.Errorf("%s: inbox is task %d, want the old one %d", tt.name, inbox, old)
+		}
+		if items := m.store.Items(inbox); len(items) != 1 || items[0].Text != "captured" {
+			t.Errorf("%s: inbox items = %+v, want the capture", tt.name, items)
+		}
+		visible := false
+		for _, tk := range m.store.Tasks() {
+			visible = visible || tk.ID == inbox
+		}
+		if !visible {
+			t.Errorf("%s: the inbox is not in the task list", tt.name)
+		}
+	}
+}
+
+func TestReopenLastDoneResumesTimer(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		done    []time.Duration // completed this long ago, one item each
+		want    int             // index of the item reopened, -1 for none
+		elapsed time.Duration
+	}{
+		{"nothing done", nil, -1, 0},
+		{"the only done item", []time.Duration{time.Hour}, 0, 10 * time.Minute},
+		{"the most recent of several", []time.Duration{time.Hour, time.Minute, 2 * time.Hour}, 1, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		id, _ := m.store.CreateTask("Reopen")
+		m.store.SaveItem(item{TaskID: id, Text: "open", Position: 0})
+		var ids []int64
+		for i, ago := range tt.done {
+			at := now.Add(-ago)
+			ids = append(ids, m.store.SaveItem(item{TaskID: id, Text: "done", Status: Done, CheckedAt: &at,
+				StartedAt: at.Add(-10 * time.Minute), FrozenDuration: 10 * time.Minute, Position: i + 1}))
+		}
+		m.openTask(id)
+		m = press(m, tea.KeyCtrlO)
+		var reopened []item
+		for _, it := range m.store.Items(id) {
+			if it.Status == Started {
+				reopened = append(reopened, it)
+			}
+		}
+		if tt.want < 0 {
+			if len(reopened) != 0 {
+				t.Errorf("%s: reopened %+v", tt.name, reopened)
+			}
+			continue
+		}
+		if len(reopened) != 1 || reopened[0].ID != ids[tt.want] {
+			t.Errorf("%s: reopened %+v, want item %d", tt.name, reopened, ids[tt.want])
+			continue
+		}
+		if got := reopened[0].elapsed(time.Now()); got < tt.elapsed || got > tt.elapsed+time.Minute {
+			t.Errorf("%s: timer resumed at %v, want about %v", tt.name, got, tt.elapsed)
+		}
+		if cur := m.currentItem(); cur == nil || cur.ID != ids[tt.want] {
+			t.Errorf("%s: cursor not on the reopened item", tt.name)
+		}
+	}
+}
+
+func TestEnterAndExitEditMode(t *testing.T) {
+	tests := []struct {
+		name string
+		nav  bool
+		text string
+	}{
+		{"insert mode", false, "Rename me"},
+		{"from nav mode", true, "Rename me"},
+		{"empty text", false, ""},
+		{"multibyte text", false, "café ☕"},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.setNavMode(tt.nav)
+		m.startPrompt("Edit", tt.text, func(*model, string, int) {})
+		if got := m.input.Value(); got != tt.text {
+			t.Errorf("%s: input = %q, want %q", tt.name, got, tt.text)
+		}
+		if got, want := m.input.Position(), len([]rune(tt.text)); got != want {
+			t.Errorf("%s: cursor at %d, want the end at %d", tt.name, got, want)
+		}
+		if !m.input.Focused() {
+			t.Errorf("%s: input not focused in edit mode", tt.name)
+		}
+		m.prompt = nil
+		m.exitEditMode()
+		if m.input.Value() != "" || m.input.Focused() == tt.nav {
+			t.Errorf("%s: after exit input = %q, focused = %v", tt.name, m.input.Value(), m.input.Focused())
+		}
+	}
+}
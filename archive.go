@@ -0,0 +1,63 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+func archiveTasks(db *sql.DB, ids []int64) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	now := formatStamp(time.Now())
+	for _, id := range ids {
+		if _, err := execDB(tx, "UPDATE tasks SET archived_at = ? WHERE id = ?", now, id); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	return len(ids), tx.Commit()
+}
+
+func unarchiveTask(db querier, code string) error {
+	res, err := execDB(db, "UPDATE tasks SET archived_at = '' WHERE code = ? COLLATE NOCASE AND archived_at != '' AND deleted_at = ''", code)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("archived task %s %w", code, ErrNotFound)
+	}
+	return nil
+}
+
+// archiveDone hides every Done task from the list after a y/n, keeping
+// its items and time for reports.
+func (m *model) archiveDone() {
+	var ids []int64
+	var preview []string
+	for _, t := range m.store.Tasks() {
+		if t.Status == Done {
+			ids = append(ids, t.ID)
+			preview = append(preview, t.Code+" "+t.Title)
+		}
+	}
+	if len(ids) == 0 {
+		m.setStatus("No completed tasks to archive")
+		return
+	}
+	m.confirm = &confirmation{
+		prompt:  fmt.Sprintf("Archive %d completed tasks? y/n", len(ids)),
+		preview: preview,
+		action: func(m *model) {
+			n, err := m.store.ArchiveTasks(ids)
+			if err != nil {
+				m.setStatus("Archive failed: " + err.Error())
+				return
+			}
+			m.reloadTasks()
+			m.setStatus(fmt.Sprintf("Archived %d tasks, \\unarchive <code> to bring one back", n))
+		},
+	}
+}
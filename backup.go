@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type backupMsg struct{}
+
+// backupDB writes a consistent copy of the database to path. VACUUM INTO
+// reads through SQLite itself, so it is safe while the app holds the DB open.
+func backupDB(db *sql.DB, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	_, err := execDB(db, "VACUUM INTO ?", path)
+	return err
+}
+
+// saveDBAs copies the database to a new file at path, checking first that
+// the target does not exist and that its directory is writable.
+func saveDBAs(db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	probe, err := os.CreateTemp(filepath.Dir(path), ".checklist-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", filepath.Dir(path), err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	_, err = execDB(db, "VACUUM INTO ?", path)
+	return err
+}
+
+// backupStamp is the time layout in backup names, which sorts by age.
+const backupStamp = "20060102-150405"
+
+// backupPrefix is where the backups of db go: a backups directory next to
+// the database file, each named after it. It is empty for an in-memory
+// database, which has nothing worth keeping.
+func backupPrefix(db *sql.DB) string {
+	var file string
+	queryRowDB(db, "SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file)
+	if file == "" {
+		return ""
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return filepath.Join(filepath.Dir(file), "backups", name)
+}
+
+func backupPath(prefix string, now time.Time) string {
+	return prefix + "-" + now.Format(backupStamp) + ".db"
+}
+
+// listBackups finds the backups made under prefix, oldest first. Only names
+// that are the prefix plus a stamp count, so the backups of another
+// database in the same directory are left alone.
+func listBackups(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	dir, name := filepath.Split(prefix)
+	entries, _ := os.ReadDir(dir)
+	var paths []string
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), name+"-")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if stamp, ok = strings.CutSuffix(stamp, ".db"); !ok {
+			continue
+		}
+		if _, err := time.Parse(backupStamp, stamp); err == nil {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func pruneBackups(prefix string, keep int) error {
+	paths := listBackups(prefix)
+	for len(paths) > keep {
+		if err := os.Remove(paths[0]); err != nil {
+			return err
+		}
+		paths = paths[1:]
+	}
+	return nil
+}
+
+func lastBackupAt(prefix string) time.Time {
+	paths := listBackups(prefix)
+	if len(paths) == 0 {
+		return time.Time{}
+	}
+	info, err := os.Stat(paths[len(paths)-1])
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (m *model) runBackup(now time.Time) {
+	if m.backupPrefix == "" {
+		return
+	}
+	if err := m.store.Backup(backupPath(m.backupPrefix, now)); err != nil {
+		m.setStatus("Backup failed: " + err.Error())
+		return
+	}
+	if err := pruneBackups(m.backupPrefix, m.cfg.BackupKeep); err != nil {
+		m.setStatus("Backup cleanup failed: " + err.Error())
+	}
+}
+
+func (m model) scheduleBackup(wait time.Duration) tea.Cmd {
+	if m.cfg.BackupInterval <= 0 || m.backupPrefix == "" {
+		return nil
+	}
+	return tea.Tick(max(wait, 0), func(time.Time) tea.Msg {
+		return backupMsg{}
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupPrefix(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, path, want string
+	}{
+		{"file database", filepath.Join(dir, "work.db"), filepath.Join(dir, "backups", "work")},
+		{"no extension", filepath.Join(dir, "plans"), filepath.Join(dir, "backups", "plans")},
+		{"in memory", memoryDB, ""},
+	}
+	for _, tt := range tests {
+		db, err := openDB(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := backupPrefix(db)
+		db.Close()
+		if got != tt.want {
+			t.Errorf("%s: backupPrefix = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPruneLeavesOtherDatabasesAlone(t *testing.T) {
+	dir := t.TempDir()
+	prefix := filepath.Join(dir, "work")
+	files := []string{
+		"work-20260101-090000.db",
+		"work-20260102-090000.db",
+		"work-20260103-090000.db",
+		"work-old-20260101-090000.db",
+		"workshop-20260101-090000.db",
+		"checklist-20260101-090000.db",
+		"work-notes.db",
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(listBackups(prefix)); got != 3 {
+		t.Fatalf("listBackups found %d backups, want 3", got)
+	}
+	if err := pruneBackups(prefix, 1); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		file string
+		kept bool
+	}{
+		{"work-20260101-090000.db", false},
+		{"work-20260102-090000.db", false},
+		{"work-20260103-090000.db", true},
+		{"work-old-20260101-090000.db", true},
+		{"workshop-20260101-090000.db", true},
+		{"checklist-20260101-090000.db", true},
+		{"work-notes.db", true},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(dir, tt.file))
+		if kept := err == nil; kept != tt.kept {
+			t.Errorf("%s: kept = %v, want %v", tt.file, kept, tt.kept)
+		}
+	}
+}
+
+func TestRunBackupWritesNextToTheDatabase(t *testing.T) {
+	dir := t.TempDir()
+	db := mustOpenDB(filepath.Join(dir, "work.db"))
+	defer db.Close()
+	m := newModel(newStore(db))
+	m.runBackup(time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local))
+	if _, err := os.Stat(filepath.Join(dir, "backups", "work-20260302-120000.db")); err != nil {
+		t.Errorf("backup not written next to the database: %v (status %q)", err, m.status)
+	}
+
+	mem := newModel(newStore(newTestDB(t)))
+	if mem.backupPrefix != "" || mem.scheduleBackup(time.Hour) != nil {
+		t.Error("an in-memory database schedules backups")
+	}
+}
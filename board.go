@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const boardColumnWidth = 26
+
+var boardColumns = []itemStatus{NotStarted, Started, Done}
+
+// columnRows lists indexes into m.items for each board column. The board
+// keeps using m.cursor as an index into m.items, so every item action works
+// unchanged on the selected card.
+func (m model) columnRows() [][]int {
+	cols := make([][]int, len(boardColumns))
+	for i, it := range m.items {
+		c := it.Status
+		if c == Skipped {
+			c = Done
+		}
+		cols[c] = append(cols[c], i)
+	}
+	return cols
+}
+
+func (m model) boardPosition() (col, row int) {
+	for c, rows := range m.columnRows() {
+		for r, i := range rows {
+			if i == m.cursor {
+				return c, r
+			}
+		}
+	}
+	return 0, 0
+}
+
+func (m model) updateBoard(msg tea.KeyMsg, input string) (model, bool) {
+	key := msg.String()
+	if input != "" && key != "up" && key != "down" {
+		return m, false
+	}
+	cols := m.columnRows()
+	col, row := m.boardPosition()
+	switch key {
+	case "up":
+		row--
+	case "down":
+		row++
+	case "left":
+		col--
+	case "right":
+		col++
+	case "shift+left", "shift+right":
+		if it := m.currentItem(); it != nil && !m.taskGone() {
+			m.shiftCard(it, key == "shift+right")
+		}
+		return m, true
+	default:
+		return m, false
+	}
+	for col >= 0 && col < len(cols) && len(cols[col]) == 0 && key != "up" && key != "down" {
+		if key == "left" {
+			col--
+		} else {
+			col++
+		}
+	}
+	if col < 0 || col >= len(cols) || len(cols[col]) == 0 {
+		return m, true
+	}
+	m.cursor = cols[col][max(min(row, len(cols[col])-1), 0)]
+	return m, true
+}
+
+func (m *model) shiftCard(it *item, forward bool) {
+	var change func(*item, time.Time)
+	switch {
+	case forward && !it.Status.closed():
+		change = cycleStatus
+	case !forward && it.Status.closed():
+		change = reopenItem
+	case !forward && it.Status == Started:
+		change = func(it *item, now time.Time) {
+			it.FrozenDuration = it.elapsed(now)
+			it.Status = NotStarted
+			it.ClockedOut = false
+		}
+	default:
+		return
+	}
+	id := it.ID
+	if m.changeItemStatus(it, change) {
+		return
+	}
+	m.reloadItems()
+	for i, it := range m.items {
+		if it.ID == id {
+			m.cursor = i
+		}
+	}
+}
+
+func (m model) viewBoard(b *strings.Builder) {
+	cols := m.columnRows()
+	height, header := 0, ""
+	for c, status := range boardColumns {
+		height = max(height, len(cols[c]))
+		header += fmt.Sprintf("%-*s", boardColumnWidth, fmt.Sprintf("%s (%d)", statusGroupNames[status], len(cols[c])))
+	}
+	b.WriteString(strings.TrimRight(header, " ") + "\n")
+	now := time.Now()
+	for r := 0; r < height; r++ {
+		line := ""
+		for c := range boardColumns {
+			cell := ""
+			if r < len(cols[c]) {
+				i := cols[c][r]
+				it := m.items[i]
+				cursor := " "
+				if i == m.cursor {
+					cursor = ">"
+				}
+				text := []rune(it.Text)
+				if it.Status == Skipped {
+					text = append([]rune(statusMarker(Skipped)+" "), text...)
+				}
+				if limit := boardColumnWidth - 12; len(text) > limit {
+					text = append(text[:limit-1], '…')
+				}
+				cell = fmt.Sprintf("%s %s", cursor, string(text))
+				if !m.hideTimers && it.Status != NotStarted {
+					cell += " " + formatDuration(it.elapsed(now))
+				}
+			}
+			line += fmt.Sprintf("%-*s", boardColumnWidth, cell)
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
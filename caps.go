@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// overCap reports the add that takes a list past its soft cap, once per
+// crossing so later adds stay quiet. A cap of 0 turns the check off.
+func overCap(n, limit int) bool {
+	return limit > 0 && n == limit+1
+}
+
+func (m *model) warnTaskCap() {
+	if n := len(m.tasks); overCap(n, m.cfg.MaxTasks) {
+		m.setStatus(fmt.Sprintf("%d tasks is over the max_tasks of %d, consider \\archive", n, m.cfg.MaxTasks))
+	}
+}
+
+func (m *model) warnItemCap(taskID int64) {
+	if n := len(m.store.Items(taskID)); overCap(n, m.cfg.MaxItems) {
+		m.setStatus(fmt.Sprintf("%d items is over the max_items of %d, consider \\split or \\carry", n, m.cfg.MaxItems))
+	}
+}
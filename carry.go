@@ -0,0 +1,75 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// moveItem puts an item at the end of another task.
+func moveItem(db querier, itemID, toTaskID int64) error {
+	var position int
+	if err := queryRowDB(db, "SELECT COALESCE(MAX(position), 0) + 1 FROM items WHERE task_id = ?", toTaskID).Scan(&position); err != nil {
+		return err
+	}
+	_, err := execDB(db, "UPDATE items SET task_id = ?, position = ? WHERE id = ?", toTaskID, position, itemID)
+	return err
+}
+
+// carryOver moves the unfinished items of a task into a new task with
+// their timers reset. Done and skipped items stay behind as the record.
+func carryOver(db *sql.DB, fromTaskID int64, title string) (int64, int, error) {
+	var open []item
+	for _, it := range loadItems(db, fromTaskID) {
+		if !it.Status.closed() {
+			open = append(open, it)
+		}
+	}
+	if len(open) == 0 {
+		return 0, 0, fmt.Errorf("nothing left to carry over")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	toTaskID, err := createTask(tx, title)
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+	now := time.Now()
+	for _, it := range open {
+		if err := moveItem(tx, it.ID, toTaskID); err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+		if _, err := execDB(tx, "UPDATE items SET status = ?, started_at = ?, checked_at = '', frozen_duration = 0, duration_seconds = 0, clocked_out = 0 WHERE id = ?",
+			NotStarted, formatStamp(now), it.ID); err != nil {
+			tx.Rollback()
+			return 0, 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	updateTaskStatus(db, fromTaskID)
+	updateTaskStatus(db, toTaskID)
+	return toTaskID, len(open), nil
+}
+
+func (m *model) carryOver(title string) {
+	if m.selectedTaskID == 0 || m.taskGone() {
+		return
+	}
+	if title == "" {
+		title = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	}
+	toTaskID, n, err := m.store.CarryOver(m.selectedTaskID, title)
+	if err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+	m.reloadTasks()
+	m.openTask(toTaskID)
+	m.setStatus(fmt.Sprintf("Carried %d items over to %s", n, title))
+}
@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestCarryOver(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []itemStatus
+		wantN    int
+		wantErr  bool
+	}{
+		{"moves open items", []itemStatus{NotStarted, Started, Done, Skipped}, 2, false},
+		{"nothing to carry", []itemStatus{Done, Skipped}, 0, true},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		from, err := createTask(db, "From")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, s := range tt.statuses {
+			saveItem(db, item{TaskID: from, Text: "x", Status: s, Position: i})
+		}
+		before := len(loadTasks(db))
+		to, n, err := carryOver(db, from, "To")
+		if (err != nil) != tt.wantErr || n != tt.wantN {
+			t.Errorf("%s: carryOver = %d, %v, want %d items, error %v", tt.name, n, err, tt.wantN, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			if got := len(loadTasks(db)); got != before {
+				t.Errorf("%s: %d tasks after a failed carry over, want %d", tt.name, got, before)
+			}
+			continue
+		}
+		for _, it := range loadItems(db, to) {
+			if it.Status != NotStarted || it.FrozenDuration != 0 {
+				t.Errorf("%s: carried item not reset: %+v", tt.name, it)
+			}
+		}
+		if got := len(loadItems(db, from)); got != len(tt.statuses)-tt.wantN {
+			t.Errorf("%s: %d items left behind, want %d", tt.name, got, len(tt.statuses)-tt.wantN)
+		}
+	}
+}
+
+func TestCarryOverRollsBackTheNewTask(t *testing.T) {
+	db := newTestDB(t)
+	from, err := createTask(db, "From")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveItem(db, item{TaskID: from, Text: "x"})
+	if _, err := execDB(db, "CREATE TRIGGER fail_move BEFORE UPDATE OF task_id ON items BEGIN SELECT RAISE(ABORT, 'boom'); END"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := carryOver(db, from, "To"); err == nil {
+		t.Fatal("carryOver succeeded despite the failing move")
+	}
+	if got := len(loadTasks(db)); got != 1 {
+		t.Errorf("%d tasks after a failed carry over, want 1", got)
+	}
+}
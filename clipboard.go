@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+func (m *model) copySelection() {
+	var text string
+	now := time.Now()
+	if m.selectedTaskID == 0 {
+		t, ok := m.currentTask()
+		if !ok {
+			return
+		}
+		items := m.store.Items(t.ID)
+		done, total := m.store.TaskProgress(t.ID)
+		text = fmt.Sprintf("%s - %s (%d/%d done, %s)", t.Code, t.Title, done, total, formatDuration(totalDuration(items, now)))
+	} else {
+		it := m.currentItem()
+		if it == nil {
+			return
+		}
+		text = formatDuration(it.elapsed(now))
+	}
+
+	if err := clipboard.WriteAll(text); err != nil {
+		m.setStatus("Clipboard unavailable: " + text)
+		return
+	}
+	m.setStatus("Copied: " + text)
+}
+
+// taskSnippet renders a task as plain text for pasting into chat, with the
+// durations lined up in a column.
+func taskSnippet(t task, items []item, now time.Time) string {
+	var b strings.Builder
+	width := 0
+	for _, it := range items {
+		width = max(width, len([]rune(it.Text)))
+	}
+	done, total := countDone(items)
+	fmt.Fprintf(&b, "%s - %s (%d/%d done, %s)\n", t.Code, t.Title, done, total, formatDuration(totalDuration(items, now)))
+	for _, it := range items {
+		pad := strings.Repeat(" ", width-len([]rune(it.Text)))
+		fmt.Fprintf(&b, "%s %s%s  %s\n", statusMarker(it.Status), it.Text, pad, formatDuration(it.elapsed(now)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (m *model) copySnippet() {
+	id := m.selectedTaskID
+	if id == 0 {
+		t, ok := m.currentTask()
+		if !ok {
+			return
+		}
+		id = t.ID
+	} else if m.taskGone() {
+		return
+	}
+	t, err := m.store.ResolveTask(command{TaskID: id})
+	if err != nil {
+		return
+	}
+	if err := clipboard.WriteAll(taskSnippet(t, m.store.Items(id), time.Now())); err != nil {
+		m.setStatus("Clipboard unavailable, use: checklist share " + t.Code)
+		return
+	}
+	m.setStatus("Copied " + t.Code + " as text")
+}
+
+var statusNames = map[string][]itemStatus{
+	"todo":    {NotStarted},
+	"started": {Started},
+	"done":    {Done},
+	"skipped": {Skipped},
+	"open":    {NotStarted, Started},
+}
+
+// parseStatuses reads a comma-separated list of status names; an empty
+// list or "all" keeps every status and returns nil.
+func parseStatuses(s string) (map[itemStatus]bool, error) {
+	if s == "" || s == "all" {
+		return nil, nil
+	}
+	include := map[itemStatus]bool{}
+	for _, name := range strings.Split(s, ",") {
+		statuses, ok := statusNames[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown status %q, use all, open, todo, started, done or skipped", name)
+		}
+		for _, st := range statuses {
+			include[st] = true
+		}
+	}
+	return include, nil
+}
+
+func taskCodes(tasks []task, include map[itemStatus]bool) []string {
+	var lines []string
+	for _, t := range tasks {
+		if include == nil || include[t.Status] {
+			lines = append(lines, t.Code+" "+t.Title)
+		}
+	}
+	return lines
+}
+
+func (m *model) copyCodes(arg string) {
+	include, err := parseStatuses(arg)
+	if err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+	lines := taskCodes(m.store.Tasks(), include)
+	if len(lines) == 0 {
+		m.setStatus("No matching tasks")
+		return
+	}
+	if err := clipboard.WriteAll(strings.Join(lines, "\n")); err != nil {
+		m.setStatus("Clipboard unavailable: " + strings.Join(lines, ", "))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Copied %d task codes", len(lines)))
+}
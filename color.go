@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+type colorLevel int
+
+const (
+	noColor colorLevel = iota
+	ansi16
+	ansi256
+	trueColor
+)
+
+func (l colorLevel) String() string {
+	return map[colorLevel]string{noColor: "none", ansi16: "16 colors", ansi256: "256 colors", trueColor: "truecolor"}[l]
+}
+
+// colors is the level the render code styles output for, detected at
+// startup and forced to noColor by -mono.
+var colors = noColor
+
+// detectColorLevel follows the usual conventions: NO_COLOR or a dumb or
+// unset TERM turn color off, COLORTERM advertises truecolor and TERM
+// names ending in 256color get the 256 color palette.
+func detectColorLevel(getenv func(string) string, tty bool) colorLevel {
+	term := getenv("TERM")
+	switch {
+	case !tty, getenv("NO_COLOR") != "", term == "", term == "dumb":
+		return noColor
+	case getenv("COLORTERM") == "truecolor", getenv("COLORTERM") == "24bit":
+		return trueColor
+	case strings.HasSuffix(term, "256color"):
+		return ansi256
+	}
+	return ansi16
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type tint int
+
+const (
+	amber tint = iota
+	red
+)
+
+// tintCodes holds the SGR parameters of each tint for the 16, 256 and
+// truecolor palettes, in that order.
+var tintCodes = map[tint][3]string{
+	amber: {"33", "38;5;214", "38;2;255;175;0"},
+	red:   {"31", "38;5;196", "38;2;255;85;85"},
+}
+
+func paint(s string, t tint) string {
+	if colors == noColor {
+		return s
+	}
+	return "\x1b[" + tintCodes[t][colors-1] + "m" + s + "\x1b[0m"
+}
+
+// ageTint picks the tint for an open item by how many calendar days ago
+// it was created: amber from warnDays, red from staleDays.
+func ageTint(created, now time.Time, warnDays, staleDays int) (tint, bool) {
+	days := int(startOfDay(now).Sub(startOfDay(created.In(now.Location()))).Round(oneDay) / oneDay)
+	switch {
+	case days >= staleDays:
+		return red, true
+	case days >= warnDays:
+		return amber, true
+	}
+	return 0, false
+}
+
+func (m model) itemMarker(it item, now time.Time) string {
+	marker := statusMarker(it.Status)
+	if !m.cfg.AgeColors || it.Status.closed() {
+		return marker
+	}
+	if t, ok := ageTint(it.CreatedAt, now, m.cfg.AgeWarnDays, m.cfg.AgeStaleDays); ok {
+		return paint(marker, t)
+	}
+	return marker
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type command struct {
+	Action string `json:"action"`
+	Task   string `json:"task"`
+	TaskID int64  `json:"task_id"`
+	ItemID int64  `json:"item_id"`
+	Title  string `json:"title"`
+	Text   string `json:"text"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+type commandResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+	ID    int64  `json:"id,omitempty"`
+	Tasks []task `json:"tasks,omitempty"`
+	Items []item `json:"items,omitempty"`
+}
+
+func runCommands(db *sql.DB, r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var c command
+		if err := dec.Decode(&c); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		res, err := execCommand(db, c)
+		if err != nil {
+			res = commandResult{Error: err.Error(), Code: errorCode(err)}
+		} else {
+			res.OK = true
+		}
+		enc.Encode(res)
+	}
+}
+
+func execCommand(db *sql.DB, c command) (commandResult, error) {
+	switch c.Action {
+	case "add_task":
+		id, err := createTask(db, c.Title)
+		if err != nil {
+			return commandResult{}, err
+		}
+		return commandResult{ID: id}, nil
+
+	case "list_tasks":
+		return commandResult{Tasks: loadTasks(db)}, nil
+
+	case "delete_task":
+		t, err := resolveTask(db, c)
+		if err != nil {
+			return commandResult{}, err
+		}
+		if err := deleteTask(db, t.ID); err != nil {
+			return commandResult{}, err
+		}
+		return commandResult{ID: t.ID}, nil
+
+	case "add_item":
+		t, err := resolveTask(db, c)
+		if err != nil {
+			return commandResult{}, err
+		}
+		text := strings.TrimSpace(c.Text)
+		if text == "" {
+			return commandResult{}, fmt.Errorf("text is required")
+		}
+		id := saveItem(db, item{
+			TaskID:    t.ID,
+			Text:      text,
+			Status:    NotStarted,
+			CreatedAt: time.Now(),
+		})
+		updateTaskStatus(db, t.ID)
+		return commandResult{ID: id}, nil
+
+	case "list_items":
+		t, err := resolveTask(db, c)
+		if err != nil {
+			return commandResult{}, err
+		}
+		return commandResult{Items: loadItems(db, t.ID)}, nil
+
+	case "delete_item":
+		it, err := resolveItem(db, c.ItemID)
+		if err != nil {
+			return commandResult{}, err
+		}
+		if err := deleteItem(db, it.ID); err != nil {
+			return commandResult{}, err
+		}
+		updateTaskStatus(db, it.TaskID)
+		return commandResult{ID: it.ID}, nil
+
+	case "toggle_item":
+		it, err := resolveItem(db, c.ItemID)
+		if err != nil {
+			return commandResult{}, err
+		}
+		cycleStatus(&it, time.Now())
+		saveItemStatus(db, it)
+		updateTaskStatus(db, it.TaskID)
+		return commandResult{ID: it.ID, Items: []item{it}}, nil
+
+	case "set":
+		if c.Key == "" {
+			return commandResult{}, fmt.Errorf("key is required")
+		}
+		setSetting(db, c.Key, c.Value)
+		return commandResult{}, nil
+	}
+	return commandResult{}, fmt.Errorf("unknown action %q", c.Action)
+}
+
+func resolveTask(db querier, c command) (task, error) {
+	for _, t := range loadTasks(db) {
+		if (c.TaskID != 0 && t.ID == c.TaskID) || (c.Task != "" && strings.EqualFold(t.Code, c.Task)) {
+			return t, nil
+		}
+	}
+	return task{}, fmt.Errorf("task %w", ErrNotFound)
+}
+
+func resolveItem(db *sql.DB, id int64) (item, error) {
+	var taskID int64
+	if err := queryRowDB(db, "SELECT task_id FROM items WHERE id = ? AND deleted_at = ''", id).Scan(&taskID); err != nil {
+		return item{}, fmt.Errorf("item %w", ErrNotFound)
+	}
+	for _, it := range loadItems(db, taskID) {
+		if it.ID == id {
+			return it, nil
+		}
+	}
+	return item{}, fmt.Errorf("item %w", ErrNotFound)
+}
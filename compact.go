@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// compactLine renders the open task, or else the task of the first running
+// item, as one line for a status bar or a small tmux pane. It queries, so
+// refreshLive calls it and View shows the result.
+func (m model) compactLine(now time.Time) string {
+	running := m.store.RunningItems()
+	t, ok := m.openedTask()
+	if !ok && len(running) > 0 {
+		t, ok = m.taskByID(running[0].TaskID)
+	}
+	if !ok {
+		return "checklist: nothing running"
+	}
+	done, total := m.store.TaskProgress(t.ID)
+	line := fmt.Sprintf("%s %d/%d", t.Code, done, total)
+	for _, it := range running {
+		if it.TaskID == t.ID {
+			return line + " " + statusMarker(it.Status) + " " + it.Text + " " + formatDuration(it.elapsed(now))
+		}
+	}
+	if m.paused {
+		return line + " paused"
+	}
+	return line + " idle"
+}
+
+// updateCompact suspends input while compact; only esc brings the full view
+// back.
+func (m model) updateCompact(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.compact = false
+	}
+	return m, nil
+}
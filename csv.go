@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type dayTotal struct {
+	Day     string
+	Code    string
+	Seconds int64
+}
+
+// dailyTotals sums completed items' time per local day of CheckedAt and
+// task, ordered by day and then task code.
+func dailyTotals(items []itemWithTask) []dayTotal {
+	type key struct{ day, code string }
+	sums := map[key]time.Duration{}
+	for _, it := range items {
+		if it.Status != Done || it.CheckedAt == nil {
+			continue
+		}
+		sums[key{it.CheckedAt.Local().Format("2006-01-02"), it.TaskCode}] += it.FrozenDuration
+	}
+	totals := []dayTotal{}
+	for k, d := range sums {
+		totals = append(totals, dayTotal{Day: k.day, Code: k.code, Seconds: durationSeconds(d)})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Day != totals[j].Day {
+			return totals[i].Day < totals[j].Day
+		}
+		return totals[i].Code < totals[j].Code
+	})
+	return totals
+}
+
+func exportDailyCSV(db querier) ([]byte, error) {
+	items, err := queryItemsWithTask(db, "AND i.status = ?", Done)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"date", "task_code", "duration_seconds"})
+	for _, t := range dailyTotals(items) {
+		w.Write([]string{t.Day, t.Code, strconv.FormatInt(t.Seconds, 10)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
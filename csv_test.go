@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportDailyCSV(t *testing.T) {
+	inZone(t, time.FixedZone("UTC+2", 2*3600))
+	day := func(d, h, m int) *time.Time {
+		at := time.Date(2026, 3, d, h, m, 0, 0, time.Local)
+		return &at
+	}
+	tests := []struct {
+		name  string
+		items []item // TaskID 1 is AB, 2 is CD
+		want  string
+	}{
+		{"one row per day and task", []item{
+			{TaskID: 1, Status: Done, CheckedAt: day(2, 10, 0), FrozenDuration: 10 * time.Minute},
+			{TaskID: 1, Status: Done, CheckedAt: day(2, 15, 0), FrozenDuration: 5 * time.Minute},
+			{TaskID: 2, Status: Done, CheckedAt: day(2, 11, 0), FrozenDuration: time.Minute},
+			{TaskID: 1, Status: Done, CheckedAt: day(3, 9, 0), FrozenDuration: time.Hour},
+		}, "2026-03-02,AB,900|2026-03-02,CD,60|2026-03-03,AB,3600"},
+		{"local midnight splits the days", []item{
+			{TaskID: 1, Status: Done, CheckedAt: day(2, 23, 59), FrozenDuration: time.Minute},
+			{TaskID: 1, Status: Done, CheckedAt: day(3, 0, 1), FrozenDuration: 2 * time.Minute},
+		}, "2026-03-02,AB,60|2026-03-03,AB,120"},
+		{"open items are left out", []item{
+			{TaskID: 2, Status: Started, FrozenDuration: time.Hour},
+			{TaskID: 2, Status: Done, CheckedAt: day(3, 12, 0), FrozenDuration: 30 * time.Second},
+		}, "2026-03-03,CD,30"},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		ids := map[int64]int64{}
+		for n, input := range []string{"AB: First", "CD: Second"} {
+			id, err := createTask(db, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids[int64(n+1)] = id
+		}
+		for _, it := range tt.items {
+			it.TaskID = ids[it.TaskID]
+			it.Text = "x"
+			it.CreatedAt = *day(1, 8, 0)
+			if saveItem(db, it) == 0 {
+				t.Fatalf("%s: could not save %+v", tt.name, it)
+			}
+		}
+		out, err := exportDailyCSV(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if lines[0] != "date,task_code,duration_seconds" {
+			t.Errorf("%s: header = %q", tt.name, lines[0])
+		}
+		if got := strings.Join(lines[1:], "|"); got != tt.want {
+			t.Errorf("%s: rows = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
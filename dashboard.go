@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type taskSort int
+
+const (
+	sortNone taskSort = iota
+	sortCode
+	sortTitle
+	sortStatus
+	sortProgress
+	sortTime
+	sortDue
+	sortActivity
+)
+
+var taskSortNames = map[string]taskSort{
+	"none":     sortNone,
+	"code":     sortCode,
+	"title":    sortTitle,
+	"status":   sortStatus,
+	"progress": sortProgress,
+	"time":     sortTime,
+	"due":      sortDue,
+	"activity": sortActivity,
+}
+
+func (s taskSort) String() string {
+	for name, v := range taskSortNames {
+		if v == s {
+			return name
+		}
+	}
+	return "none"
+}
+
+// parseTaskSort reads a sort preference such as "title" or "progress desc".
+func parseTaskSort(s string) (by taskSort, desc bool, ok bool) {
+	name, dir, _ := strings.Cut(strings.TrimSpace(s), " ")
+	by, ok = taskSortNames[name]
+	switch strings.TrimSpace(dir) {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		ok = false
+	}
+	return by, desc, ok
+}
+
+func formatTaskSort(by taskSort, desc bool) string {
+	if desc {
+		return by.String() + " desc"
+	}
+	return by.String()
+}
+
+func (m *model) reloadTasks() {
+	m.tasks = m.store.Tasks()
+	m.taskItems = map[int64][]item{}
+	for _, it := range m.store.AllItems() {
+		m.taskItems[it.TaskID] = append(m.taskItems[it.TaskID], it)
+	}
+	sortTasks(m.tasks, m.taskSort, m.sortDesc, m.taskItems, time.Now())
+	if m.grouped {
+		sort.SliceStable(m.tasks, func(i, j int) bool {
+			return statusGroups[m.tasks[i].Status] < statusGroups[m.tasks[j].Status]
+		})
+	}
+	m.clampCursor()
+}
+
+func sortTasks(tasks []task, by taskSort, desc bool, items map[int64][]item, now time.Time) {
+	less := map[taskSort]func(a, b task) bool{
+		sortCode:   func(a, b task) bool { return a.Code < b.Code },
+		sortTitle:  func(a, b task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+		sortStatus: func(a, b task) bool { return a.Status < b.Status },
+		sortProgress: func(a, b task) bool {
+			return progressRatio(items[a.ID]) < progressRatio(items[b.ID])
+		},
+		sortTime: func(a, b task) bool {
+			return totalDuration(items[a.ID], now) > totalDuration(items[b.ID], now)
+		},
+		sortDue: func(a, b task) bool {
+			if a.Due == nil || b.Due == nil {
+				return a.Due != nil
+			}
+			return a.Due.Before(*b.Due)
+		},
+		sortActivity: func(a, b task) bool {
+			if a.LastActivity == nil || b.LastActivity == nil {
+				return a.LastActivity != nil
+			}
+			return a.LastActivity.After(*b.LastActivity)
+		},
+	}[by]
+	if less == nil {
+		return
+	}
+	if desc {
+		sort.SliceStable(tasks, func(i, j int) bool { return less(tasks[j], tasks[i]) })
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) })
+}
+
+var statusGroups = map[itemStatus]int{Started: 0, NotStarted: 1, Done: 2}
+
+var statusGroupNames = map[itemStatus]string{Started: "In Progress", NotStarted: "Not Started", Done: "Done"}
+
+// groupHeader returns the section header to print before task i when the
+// list is grouped and i opens a new status group.
+func (m model) groupHeader(i int) string {
+	if !m.grouped || (i > 0 && m.tasks[i-1].Status == m.tasks[i].Status) {
+		return ""
+	}
+	header := statusGroupNames[m.tasks[i].Status] + "\n"
+	if i > 0 {
+		header = "\n" + header
+	}
+	return header
+}
+
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func activityLabel(at *time.Time, now time.Time) string {
+	if at == nil {
+		return "no activity"
+	}
+	return "active " + relativeTime(*at, now)
+}
+
+func progressRatio(items []item) float64 {
+	done, total := countDone(items)
+	if total == 0 {
+		return 0
+	}
+	return float64(done) / float64(total)
+}
+
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+func dueLabel(due *time.Time, now time.Time) string {
+	if due == nil {
+		return ""
+	}
+	days := int(startOfDay(*due).Sub(startOfDay(now)).Hours() / 24)
+	switch {
+	case days < 0:
+		return fmt.Sprintf("overdue %dd", -days)
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	}
+	return fmt.Sprintf("due in %dd", days)
+}
+
+// parseDue accepts a date (2006-01-02), "today", "tomorrow", a bare number
+// of days from today or a duration from now such as "3 days".
+func parseDue(s string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(s) {
+	case "today":
+		return startOfDay(now), nil
+	case "tomorrow":
+		return startOfDay(now).AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return time.Time{}, fmt.Errorf("date %q is in the past", s)
+		}
+		return startOfDay(now).AddDate(0, 0, n), nil
+	}
+	if d, err := parseDuration(s); err == nil {
+		return startOfDay(now.Add(d)), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+func (m model) viewDashboard(b *strings.Builder) {
+	now := time.Now()
+	width := 0
+	for _, t := range m.tasks {
+		width = max(width, min(len([]rune(t.Title)), 30))
+	}
+	for i, t := range m.tasks {
+		b.WriteString(m.groupHeader(i))
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		if m.inlineEditing(i) {
+			b.WriteString(fmt.Sprintf("%s %s %s\n", cursor, statusMarker(t.Status), m.inlineInput()))
+			continue
+		}
+		items := m.taskItems[t.ID]
+		done, counted := countDone(items)
+		title := []rune(t.Title)
+		if len(title) > width {
+			title = append(title[:width-1], '…')
+		}
+		activity := ""
+		if m.cfg.ShowActivity {
+			activity = activityLabel(t.LastActivity, now)
+		}
+		total := formatDuration(totalDuration(items, now))
+		if m.hideTimers {
+			total = ""
+		}
+		line := fmt.Sprintf("%s %s %s%s%-6s %-*s%-2s %s %3d/%-3d %8s  %-14s %s  %s",
+			cursor, statusMarker(t.Status), m.numberPrefix(i), m.idPrefix(t.ID), t.Code, width, string(title), refMarker(t.Ref),
+			progressBar(done, counted, 10), done, counted,
+			total, dueLabel(t.Due, now), activity, goalLabel(t, items, now))
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
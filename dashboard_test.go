@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSortTasks(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	early, late := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	tasks := []task{
+		{ID: 1, Code: "B", Title: "beta", Status: Done, Due: &late, LastActivity: &early},
+		{ID: 2, Code: "C", Title: "Alpha", Status: NotStarted},
+		{ID: 3, Code: "A", Title: "gamma", Status: Started, Due: &early, LastActivity: &late},
+	}
+	items := map[int64][]item{
+		1: {{Status: Done, FrozenDuration: time.Hour}},
+		2: {{Status: NotStarted}, {Status: NotStarted}},
+		3: {{Status: Done, FrozenDuration: 2 * time.Hour}, {Status: NotStarted}},
+	}
+	tests := []struct {
+		by   taskSort
+		desc bool
+		want string
+	}{
+		{sortNone, false, "B,C,A"},
+		{sortCode, false, "A,B,C"},
+		{sortCode, true, "C,B,A"},
+		{sortTitle, false, "C,B,A"},
+		{sortStatus, false, "C,A,B"},
+		{sortProgress, false, "C,A,B"},
+		{sortProgress, true, "B,A,C"},
+		{sortTime, false, "A,B,C"},
+		{sortDue, false, "A,B,C"},
+		{sortActivity, false, "A,B,C"},
+		{sortActivity, true, "C,B,A"},
+	}
+	for _, tt := range tests {
+		got := append([]task(nil), tasks...)
+		sortTasks(got, tt.by, tt.desc, items, now)
+		var codes []string
+		for _, t := range got {
+			codes = append(codes, t.Code)
+		}
+		if s := strings.Join(codes, ","); s != tt.want {
+			t.Errorf("sort %s: order = %s, want %s", formatTaskSort(tt.by, tt.desc), s, tt.want)
+		}
+	}
+}
+
+func TestParseTaskSort(t *testing.T) {
+	tests := []struct {
+		in   string
+		by   taskSort
+		desc bool
+		ok   bool
+	}{
+		{"title", sortTitle, false, true},
+		{"progress desc", sortProgress, true, true},
+		{" code asc ", sortCode, false, true},
+		{"activity  desc", sortActivity, true, true},
+		{"size", sortNone, false, false},
+		{"title sideways", sortTitle, false, false},
+	}
+	for _, tt := range tests {
+		by, desc, ok := parseTaskSort(tt.in)
+		if ok != tt.ok || ok && (by != tt.by || desc != tt.desc) {
+			t.Errorf("parseTaskSort(%q) = %v, %v, %v, want %v, %v, %v", tt.in, by, desc, ok, tt.by, tt.desc, tt.ok)
+		}
+	}
+}
+
+func TestTaskSortPersists(t *testing.T) {
+	db := newTestDB(t)
+	m := newModel(newStore(db))
+	m = press(m, "\\sort title desc", tea.KeyEnter)
+	again := newModel(newStore(db))
+	if again.taskSort != sortTitle || !again.sortDesc {
+		t.Errorf("restarted with sort %s, want title desc", formatTaskSort(again.taskSort, again.sortDesc))
+	}
+}
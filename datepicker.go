@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// datePicker is a month grid for choosing a day with the arrow keys; pick
+// runs on Enter with the chosen day, or nil when x clears the date.
+type datePicker struct {
+	day  time.Time
+	pick func(m *model, day *time.Time)
+}
+
+// move steps the selection for a key and reports whether the key was one
+// of the picker's. Up and down move by a week, pgup and pgdown by a month.
+func (p *datePicker) move(key string, now time.Time) bool {
+	switch key {
+	case "left", "h":
+		p.day = p.day.AddDate(0, 0, -1)
+	case "right", "l":
+		p.day = p.day.AddDate(0, 0, 1)
+	case "up", "k":
+		p.day = p.day.AddDate(0, 0, -7)
+	case "down", "j":
+		p.day = p.day.AddDate(0, 0, 7)
+	case "pgup":
+		p.day = addMonths(p.day, -1)
+	case "pgdown":
+		p.day = addMonths(p.day, 1)
+	case "t":
+		p.day = startOfDay(now)
+	default:
+		return false
+	}
+	return true
+}
+
+// addMonths keeps the day of the month where it can and otherwise lands on
+// the last day, so Jan 31 plus a month is the end of February.
+func addMonths(day time.Time, n int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(day.Day(), last), 0, 0, 0, 0, day.Location())
+}
+
+func (m *model) openDatePicker(from *time.Time, pick func(m *model, day *time.Time)) {
+	day := startOfDay(time.Now())
+	if from != nil {
+		day = startOfDay(*from)
+	}
+	m.datePicker = &datePicker{day: day, pick: pick}
+}
+
+func (m model) updateDatePicker(msg tea.KeyMsg) (model, tea.Cmd) {
+	p := m.datePicker
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "esc":
+		m.datePicker = nil
+	case "enter":
+		m.datePicker = nil
+		day := p.day
+		p.pick(&m, &day)
+	case "x":
+		m.datePicker = nil
+		p.pick(&m, nil)
+	default:
+		p.move(msg.String(), time.Now())
+	}
+	return m, nil
+}
+
+// viewDatePicker draws the selected day's month with weeks starting on
+// Monday, marking the selection with brackets and today with a dot.
+func (m model) viewDatePicker(b *strings.Builder) {
+	p := m.datePicker
+	today := startOfDay(time.Now())
+	first := time.Date(p.day.Year(), p.day.Month(), 1, 0, 0, 0, 0, p.day.Location())
+	b.WriteString(fmt.Sprintf("%s\n\n", first.Format("January 2006")))
+	b.WriteString(" Mo  Tu  We  Th  Fr  Sa  Su\n")
+	b.WriteString(strings.Repeat("    ", (int(first.Weekday())+6)%7))
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		cell := fmt.Sprintf(" %2d ", d.Day())
+		switch {
+		case d.Equal(p.day):
+			cell = fmt.Sprintf("[%2d]", d.Day())
+		case d.Equal(today):
+			cell = fmt.Sprintf(" %2d•", d.Day())
+		}
+		b.WriteString(cell)
+		if d.Weekday() == time.Sunday {
+			b.WriteString("\n")
+		}
+	}
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n" + p.day.Format("Mon 2006-01-02"))
+	b.WriteString("\n\narrows to move • pgup/pgdown for months • t for today • [Enter] to pick • x to clear • esc to cancel")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slowLog receives every query slower than slowQuery when CHRONOLIST_DEBUG
+// is set. It is nil otherwise and the helpers skip the timing.
+var (
+	slowLog   *log.Logger
+	slowQuery = 50 * time.Millisecond
+)
+
+// openSlowLog turns the log on when CHRONOLIST_DEBUG is true. It writes to
+// CHRONOLIST_DEBUG_LOG, or chronolist-debug.log in the temp directory, and
+// CHRONOLIST_SLOW sets the threshold as a duration like 20ms.
+func openSlowLog() error {
+	on, err := strconv.ParseBool(os.Getenv("CHRONOLIST_DEBUG"))
+	if err != nil || !on {
+		return nil
+	}
+	path := os.Getenv("CHRONOLIST_DEBUG_LOG")
+	if path == "" {
+		path = filepath.Join(os.TempDir(), "chronolist-debug.log")
+	}
+	if d, err := time.ParseDuration(os.Getenv("CHRONOLIST_SLOW")); err == nil && d >= 0 {
+		slowQuery = d
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	slowLog = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	return nil
+}
+
+func logSlow(query string, start time.Time) {
+	if took := time.Since(start); took >= slowQuery {
+		slowLog.Printf("%s %s", took.Round(time.Microsecond), queryName(query))
+	}
+}
+
+// queryName squeezes a query onto one line, cut short enough to scan.
+func queryName(query string) string {
+	name := strings.Join(strings.Fields(query), " ")
+	if r := []rune(name); len(r) > 100 {
+		name = string(r[:100]) + "…"
+	}
+	return name
+}
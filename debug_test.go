@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenSlowLogTreatsDebugAsAFlag(t *testing.T) {
+	defer func() { slowLog = nil }()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "slow.log")
+	t.Setenv("CHRONOLIST_DEBUG", "1")
+	t.Setenv("CHRONOLIST_DEBUG_LOG", path)
+	if err := openSlowLog(); err != nil {
+		t.Fatal(err)
+	}
+	if slowLog == nil {
+		t.Fatal("CHRONOLIST_DEBUG=1 did not turn the log on")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("log file not created at CHRONOLIST_DEBUG_LOG: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "1")); err == nil {
+		t.Error("created a log file named after the flag value")
+	}
+}
+
+func TestSlowLogTimesRowReading(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(q time.Duration) { slowLog, slowQuery = nil, q }(slowQuery)
+	slowLog, slowQuery = log.New(&buf, "", 0), 20*time.Millisecond
+
+	db := newTestDB(t)
+	rows, err := queryDB(db, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		time.Sleep(30 * time.Millisecond)
+	}
+	rows.Close()
+	if !strings.Contains(buf.String(), "SELECT 1") {
+		t.Errorf("slow row reading was not logged: %q", buf.String())
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m *model) openDetail() {
+	if it := m.currentItem(); it != nil && !m.taskGone() {
+		m.detailID = it.ID
+	}
+}
+
+func (m model) updateDetail(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, m.quit()
+	}
+	m.detailID = 0
+	return m, nil
+}
+
+func (m model) viewDetail(b *strings.Builder) {
+	var it *item
+	for n := range m.items {
+		if m.items[n].ID == m.detailID {
+			it = &m.items[n]
+		}
+	}
+	if it == nil {
+		b.WriteString("The item no longer exists\n\nany key to go back")
+		return
+	}
+	now := time.Now()
+	stamp := func(t time.Time) string { return t.Format("2006-01-02 15:04:05") }
+
+	b.WriteString(statusMarker(it.Status) + " " + it.Text + "\n\n")
+	fields := [][2]string{
+		{"ID", fmt.Sprint(it.ID)},
+		{"Added", stamp(it.CreatedAt)},
+	}
+	if it.Status != NotStarted {
+		fields = append(fields, [2]string{"Started", stamp(it.StartedAt)})
+	}
+	if it.CheckedAt != nil {
+		fields = append(fields, [2]string{"Done", stamp(*it.CheckedAt)})
+	}
+	fields = append(fields, [2]string{"Time spent", formatDuration(it.elapsed(now))})
+	if it.Estimate > 0 {
+		fields = append(fields, [2]string{"Estimate", formatDuration(it.Estimate)})
+	}
+	if it.ClockedOut {
+		fields = append(fields, [2]string{"Clock", "clocked out"})
+	}
+	if it.Pomodoros > 0 {
+		fields = append(fields, [2]string{"Pomodoros", fmt.Sprint(it.Pomodoros)})
+	}
+	if it.Priority {
+		fields = append(fields, [2]string{"Priority", "high"})
+	}
+	if it.Ref != "" {
+		fields = append(fields, [2]string{"Link", it.Ref})
+	}
+	for _, f := range fields {
+		b.WriteString(fmt.Sprintf("%-11s %s\n", f[0]+":", f[1]))
+	}
+	if it.Note != "" {
+		b.WriteString("\nNote:\n" + it.Note + "\n")
+	}
+	b.WriteString("\nany key to go back")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationTokenRe = regexp.MustCompile(`\d+(?:\.\d+)?|\.\d+|[a-z]+`)
+
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+var durationNumberWords = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "twelve": 12, "fifteen": 15,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "ninety": 90,
+	"half": 0.5, "quarter": 0.25,
+}
+
+// smallerUnit is the unit a bare number after a unit is read in, so
+// "1h 30" is 1h30m.
+var smallerUnit = map[time.Duration]time.Duration{
+	7 * 24 * time.Hour: 24 * time.Hour,
+	24 * time.Hour:     time.Hour,
+	time.Hour:          time.Minute,
+	time.Minute:        time.Second,
+}
+
+// parseDuration accepts Go durations ("1h30m") as well as looser phrasings
+// such as "1.5h", "90 mins", "2 days", "half an hour" or "an hour and a half".
+// A number on its own is read as minutes, and a bare number after a unit in
+// the next smaller unit ("2 hours 30"). Number words need a unit, and
+// negative durations are rejected.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(s, "-") {
+		return 0, fmt.Errorf("duration %q is negative", s)
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	tokens := durationTokenRe.FindAllString(s, -1)
+	if len(tokens) == 0 || strings.Join(tokens, "") != strings.Map(dropSeparators, s) {
+		return 0, fmt.Errorf("unrecognized duration %q", s)
+	}
+
+	var total, lastUnit time.Duration
+	var qty float64
+	hasQty, joining, inWords := false, false, false
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if unit, ok := durationUnits[tok]; ok {
+			if !hasQty {
+				return 0, fmt.Errorf("unrecognized duration %q: %q has no amount", s, tok)
+			}
+			total += time.Duration(qty * float64(unit))
+			lastUnit = unit
+			qty, hasQty, joining, inWords = 0, false, false, false
+			continue
+		}
+
+		var n float64
+		if v, err := strconv.ParseFloat(tok, 64); err == nil {
+			n = v
+		} else if v, ok := durationNumberWords[tok]; ok {
+			n = v
+			inWords = true
+			// "a half", "half an", "quarter of an" read as a single fraction.
+			if (tok == "a" || tok == "an") && i+1 < len(tokens) && (tokens[i+1] == "half" || tokens[i+1] == "quarter") {
+				i++
+				n = durationNumberWords[tokens[i]]
+			}
+			if tok == "half" || tok == "quarter" || n < 1 {
+				if i+1 < len(tokens) && tokens[i+1] == "of" {
+					i++
+				}
+				if i+1 < len(tokens) && (tokens[i+1] == "a" || tokens[i+1] == "an") {
+					i++
+				}
+			}
+		} else if tok == "and" {
+			joining = hasQty || lastUnit != 0
+			continue
+		} else {
+			return 0, fmt.Errorf("unrecognized duration %q: unknown word %q", s, tok)
+		}
+
+		switch {
+		case hasQty && joining:
+			qty += n
+		case hasQty:
+			return 0, fmt.Errorf("unrecognized duration %q", s)
+		default:
+			qty, hasQty = n, true
+		}
+		joining = false
+	}
+
+	if hasQty {
+		unit := time.Minute
+		switch {
+		case inWords && lastUnit == 0:
+			return 0, fmt.Errorf("unrecognized duration %q: no unit", s)
+		case inWords && qty < 1:
+			// "an hour and a half" is a fraction of the unit before it.
+			unit = lastUnit
+		case lastUnit != 0:
+			if unit = smallerUnit[lastUnit]; unit == 0 {
+				return 0, fmt.Errorf("unrecognized duration %q: %s has no unit", s, tokens[len(tokens)-1])
+			}
+		}
+		total += time.Duration(qty * float64(unit))
+	}
+	return total, nil
+}
+
+// parseAmount is parseDuration for time that must be more than nothing,
+// such as time spent, an estimate or a goal.
+func parseAmount(s string) (time.Duration, error) {
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be more than zero", s)
+	}
+	return d, nil
+}
+
+func dropSeparators(r rune) rune {
+	switch r {
+	case ' ', '\t', ',', '-':
+		return -1
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAmountRejectsNothingAndNegatives(t *testing.T) {
+	for _, in := range []string{"-5m", "-1h30m", "- 5 min", "0", "0s", "0 minutes"} {
+		if d, err := parseAmount(in); err == nil {
+			t.Errorf("parseAmount(%q) = %s, want an error", in, d)
+		}
+	}
+	if d, err := parseAmount("5m"); err != nil || d != 5*time.Minute {
+		t.Errorf("parseAmount(5m) = %s, %v", d, err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"90 mins", 90 * time.Minute},
+		{"90 minutes", 90 * time.Minute},
+		{"45", 45 * time.Minute},
+		{"2 days", 48 * time.Hour},
+		{"1 week", 7 * 24 * time.Hour},
+		{"half an hour", 30 * time.Minute},
+		{"an hour", time.Hour},
+		{"an hour and a half", 90 * time.Minute},
+		{"a quarter of an hour", 15 * time.Minute},
+		{"two hours", 2 * time.Hour},
+		{"1 hour 15 minutes", 75 * time.Minute},
+		{"1h, 20m", 80 * time.Minute},
+		{"  2 HRS ", 2 * time.Hour},
+		{".5h", 30 * time.Minute},
+		{"1h 30", 90 * time.Minute},
+		{"2 hours 30", 150 * time.Minute},
+		{"1 hour 2", 62 * time.Minute},
+		{"1 hour and 15", 75 * time.Minute},
+		{"1 day 4", 28 * time.Hour},
+		{"1 week 2", 9 * 24 * time.Hour},
+		{"5m 30", 5*time.Minute + 30*time.Second},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDurationRejects(t *testing.T) {
+	for _, in := range []string{"", "soon", "hours", "5 parsecs", "1 2 h", "-5m", "-2 days", "3h ago", "a", "two", "half", "30s 5"} {
+		if d, err := parseDuration(in); err == nil {
+			t.Errorf("parseDuration(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.Local) }
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"today", day(10)},
+		{"Tomorrow", day(11)},
+		{"2026-03-20", day(20)},
+		{"0", day(10)},
+		{"3", day(13)},
+		{"3 days", day(13)},
+		{"1 week", day(17)},
+	}
+	for _, tt := range tests {
+		got, err := parseDue(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseDue(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"-1", "-3 days", "someday", "2026-13-01"} {
+		if got, err := parseDue(in, now); err == nil {
+			t.Errorf("parseDue(%q) = %s, want an error", in, got)
+		}
+	}
+}
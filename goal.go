@@ -0,0 +1,75 @@
+package main
+
+import (
+	"time"
+)
+
+// workedToday sums the part of each item's time that falls on now's local
+// day. A clocked-out or skipped item is taken to have stopped at
+// StartedAt+FrozenDuration, since that is all that is recorded.
+func workedToday(items []item, now time.Time) time.Duration {
+	day := startOfDay(now)
+	var total time.Duration
+	for _, it := range items {
+		var end time.Time
+		switch {
+		case it.Status == NotStarted:
+			continue
+		case it.Status == Done && it.CheckedAt != nil:
+			end = *it.CheckedAt
+		case it.Status == Started && !it.ClockedOut:
+			end = now
+		default:
+			end = it.StartedAt.Add(it.FrozenDuration)
+		}
+		start := end.Add(-it.elapsed(end))
+		if start.Before(day) {
+			start = day
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// loadWorkedItems loads the items of a task that have time on them, which
+// are all workedToday looks at.
+func loadWorkedItems(db querier, taskID int64) []item {
+	return queryItems(db, "WHERE task_id = ? AND status != ? AND deleted_at = '' ORDER BY position, id", taskID, NotStarted)
+}
+
+func goalLabel(t task, items []item, now time.Time) string {
+	if t.DailyGoal == 0 {
+		return ""
+	}
+	return formatDuration(workedToday(items, now)) + " / " + formatDuration(t.DailyGoal) + " today"
+}
+
+func (m *model) setGoal(arg string) {
+	taskID := m.selectedTaskID
+	if taskID == 0 {
+		t, ok := m.currentTask()
+		if !ok {
+			return
+		}
+		taskID = t.ID
+	} else if m.taskGone() {
+		return
+	}
+	var d time.Duration
+	if arg != "" {
+		var err error
+		if d, err = parseAmount(arg); err != nil {
+			m.setStatus(err.Error())
+			return
+		}
+	}
+	m.store.SetTaskGoal(taskID, d)
+	m.reloadTasks()
+	if d == 0 {
+		m.setStatus("Daily goal cleared")
+	} else {
+		m.setStatus("Daily goal set to " + formatDuration(d))
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkedToday(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	yesterday := now.AddDate(0, 0, -1)
+	doneAt := now.Add(-time.Hour)
+	tests := []struct {
+		name string
+		it   item
+		want time.Duration
+	}{
+		{"not started", item{Status: NotStarted, FrozenDuration: time.Hour}, 0},
+		{"running since this morning", item{Status: Started, StartedAt: now.Add(-2 * time.Hour)}, 2 * time.Hour},
+		{"running since yesterday", item{Status: Started, StartedAt: yesterday}, 12 * time.Hour},
+		{"done today", item{Status: Done, FrozenDuration: 30 * time.Minute, CheckedAt: &doneAt}, 30 * time.Minute},
+		{"done yesterday", item{Status: Done, FrozenDuration: time.Hour, CheckedAt: &yesterday}, 0},
+	}
+	for _, tt := range tests {
+		if got := workedToday([]item{tt.it}, now); got != tt.want {
+			t.Errorf("%s: workedToday = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWorkedItemsSkipsUntouched(t *testing.T) {
+	db := newTestDB(t)
+	id, _ := createTask(db, "Goal")
+	for _, s := range []itemStatus{NotStarted, Started, Done, Skipped} {
+		saveItem(db, item{TaskID: id, Text: "x", Status: s})
+	}
+	if got := len(loadWorkedItems(db, id)); got != 3 {
+		t.Errorf("loadWorkedItems returned %d items, want 3", got)
+	}
+}
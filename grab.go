@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// grab holds the item being carried and the order to restore on esc. Only
+// items have a position column, so tasks cannot be grabbed. A drop sets the
+// model's manualOrder, which stops the done_last and priority_first re-sort
+// from moving the item straight back until the task is left.
+type grab struct {
+	id    int64
+	order []item
+}
+
+func setItemPositions(db *sql.DB, items []item) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for i, it := range items {
+		if _, err := execDB(tx, "UPDATE items SET position = ? WHERE id = ?", i+1, it.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (m *model) startGrab() {
+	it := m.currentItem()
+	if it == nil || m.taskGone() {
+		return
+	}
+	if m.itemFilter != showAll || m.board {
+		m.setStatus("Show all items in the list to reorder")
+		return
+	}
+	m.grab = &grab{id: it.ID, order: append([]item(nil), m.items...)}
+	m.setStatus("Moving " + it.Text + ": ↑/↓ to move, ctrl+g or enter to drop, esc to cancel")
+}
+
+func (m model) updateGrab(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "up", "k":
+		m.carry(-1)
+	case "down", "j":
+		m.carry(1)
+	case "ctrl+g", "enter":
+		text := m.items[m.cursor].Text
+		m.grab = nil
+		if err := m.store.SetItemPositions(m.items); err != nil {
+			m.setStatus("Move failed: " + err.Error())
+		} else {
+			m.setStatus("Moved " + text)
+			if m.cfg.DoneLast || m.cfg.PriorityFirst {
+				m.manualOrder = true
+				m.setStatus("Moved " + text + "; sorting is off until you leave the task")
+			}
+		}
+		m.reloadItems()
+	case "esc":
+		m.items = m.grab.order
+		for i, it := range m.items {
+			if it.ID == m.grab.id {
+				m.cursor = i
+			}
+		}
+		m.grab = nil
+		m.setStatus("Move cancelled")
+	}
+	return m, nil
+}
+
+func (m *model) carry(delta int) {
+	to := m.cursor + delta
+	if to < 0 || to >= len(m.items) {
+		return
+	}
+	m.items[m.cursor], m.items[to] = m.items[to], m.items[m.cursor]
+	m.cursor = to
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGrabDropSurvivesResort(t *testing.T) {
+	tests := []struct {
+		name                    string
+		doneLast, priorityFirst bool
+		items                   []item
+		keys                    []any
+		want                    string
+	}{
+		{
+			name:     "done_last",
+			doneLast: true,
+			items:    []item{{Text: "a"}, {Text: "b", Status: Done}},
+			keys:     []any{tea.KeyCtrlG, tea.KeyUp, tea.KeyEnter},
+			want:     "b,a",
+		},
+		{
+			name:          "priority_first",
+			priorityFirst: true,
+			items:         []item{{Text: "a", Priority: true}, {Text: "b"}},
+			keys:          []any{tea.KeyCtrlG, tea.KeyDown, tea.KeyEnter},
+			want:          "b,a",
+		},
+		{
+			name:  "no re-sort",
+			items: []item{{Text: "a"}, {Text: "b"}},
+			keys:  []any{tea.KeyCtrlG, tea.KeyDown, tea.KeyEnter},
+			want:  "b,a",
+		},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.cfg.DoneLast, m.cfg.PriorityFirst = tt.doneLast, tt.priorityFirst
+		id, err := m.store.CreateTask("Order")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, it := range tt.items {
+			it.TaskID, it.Position = id, i+1
+			m.store.SaveItem(it)
+		}
+		m.reloadTasks()
+		m.openTask(id)
+		if tt.doneLast {
+			m.cursor = 1
+		}
+		m = press(m, tt.keys...)
+		if got := itemTexts(m.items); got != tt.want {
+			t.Errorf("%s: order after drop = %s, want %s", tt.name, got, tt.want)
+		}
+		m.reloadItems()
+		if got := itemTexts(m.items); got != tt.want {
+			t.Errorf("%s: order after reload = %s, want %s", tt.name, got, tt.want)
+		}
+		if resorts := tt.doneLast || tt.priorityFirst; m.manualOrder != resorts || strings.Contains(m.View(), "(manual order)") != resorts {
+			t.Errorf("%s: manual order = %v, want %v", tt.name, m.manualOrder, resorts)
+		}
+		m.closeTask()
+		m.openTask(id)
+		if m.manualOrder {
+			t.Errorf("%s: manual order outlived leaving the task", tt.name)
+		}
+	}
+}
+
+func itemTexts(items []item) string {
+	var texts []string
+	for _, it := range items {
+		texts = append(texts, it.Text)
+	}
+	return strings.Join(texts, ",")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type heatmapView struct {
+	days   int
+	counts []int
+}
+
+func loadCompletions(db querier, since time.Time) []time.Time {
+	times := []time.Time{}
+	rows, err := queryDB(db, `SELECT checked_at FROM items
+		WHERE status = ? AND checked_at >= ? AND deleted_at = ''
+		AND task_id IN (SELECT id FROM tasks WHERE deleted_at = '')`, Done, formatStamp(since))
+	if err != nil {
+		return times
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s string
+		rows.Scan(&s)
+		if t, err := parseStamp(s); err == nil {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// bucketByDay counts the times falling on each of the last n days, oldest
+// first, so the final bucket is today.
+func bucketByDay(times []time.Time, now time.Time, n int) []int {
+	counts := make([]int, n)
+	today := startOfDay(now)
+	for _, t := range times {
+		days := int(math.Round(today.Sub(startOfDay(t.In(now.Location()))).Hours() / 24))
+		if days >= 0 && days < n {
+			counts[n-1-days]++
+		}
+	}
+	return counts
+}
+
+var sparkBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+func sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	var b strings.Builder
+	for _, c := range counts {
+		level := 0
+		if c > 0 {
+			level = 1 + c*(len(sparkBlocks)-2)/peak
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+func (m *model) openHeatmap(arg string) {
+	days := 30
+	switch arg {
+	case "", "30":
+	case "7":
+		days = 7
+	default:
+		m.setStatus("Usage: \\heatmap [7|30]")
+		return
+	}
+	now := time.Now()
+	since := startOfDay(now).AddDate(0, 0, 1-days)
+	m.heatmap = &heatmapView{days: days, counts: bucketByDay(m.store.Completions(since), now, days)}
+}
+
+func (m model) updateHeatmap(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+	case "tab":
+		days := 7
+		if m.heatmap.days == 7 {
+			days = 30
+		}
+		m.openHeatmap(fmt.Sprint(days))
+	default:
+		m.heatmap = nil
+	}
+	return m, nil
+}
+
+func (m model) viewHeatmap(b *strings.Builder) {
+	h := m.heatmap
+	total, best := 0, 0
+	for _, c := range h.counts {
+		total += c
+		best = max(best, c)
+	}
+	now := time.Now()
+	from := startOfDay(now).AddDate(0, 0, 1-h.days)
+	b.WriteString(fmt.Sprintf("Completions, last %d days\n\n", h.days))
+	if total == 0 {
+		b.WriteString(m.emptyState("Nothing completed yet in this period"))
+		b.WriteString("\ntab to switch between 7 and 30 days • any other key to go back")
+		return
+	}
+	b.WriteString("|" + sparkline(h.counts) + "|\n")
+	b.WriteString(from.Format("Jan 2") + " to today\n\n")
+	b.WriteString(fmt.Sprintf("%d completed, best day %d\n", total, best))
+	b.WriteString("\ntab to switch between 7 and 30 days • any other key to go back")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The footer keeps to the essentials; F1 lists everything.
+const (
+	taskFooter = "↑/↓ to move • [Enter] to select • +title to add • F1 for all keys • \\q to quit"
+	itemFooter = "↑/↓ to move • [Space] to toggle • [Enter] for details • F1 for all keys • esc to go back"
+
+	taskHints = "↑/↓ to move • [Enter] to select • [Space] to set the status by hand • F2 to rename • +[CODE: ]title to add • \\b to batch add • ctrl+b or \\i to capture • \\d to delete • \\due <date|pick> • \\goal <duration> a day • \\reset to clear timers • \\ref <url> • ctrl+r to open link • \\sort <field> [desc] • \\group by status • \\pause to pause all timers • \\archive to hide done tasks • \\unarchive <code> • \\trash • \\heatmap • \\stats • \\report <from> [to] for time spent • \\csv <path> for daily totals • \\compact for one line • \\saveas <path> • \\load <file> [title] for a checklist file • ctrl+g for dashboard • ctrl+s for next item • ctrl+y to copy • \\share to copy as text • ctrl+k or \\codes [statuses] to copy task codes • F5 to refresh • tab to show IDs • ctrl+n to number, alt+digit to jump • ctrl+q to hide timers • F1 for help • ctrl+h to hide hints • esc to go back • \\q to quit"
+	itemHints = "↑/↓ to move • [Space] to toggle • ctrl+d to complete and advance • [Enter] for details • F2 to rename • ctrl+t to clock in/out • \\pause to pause all timers • ctrl+s for next item • ctrl+g to grab and move • ctrl+o to reopen last done • alt+[/] for prev/next task • ctrl+l for clock times • ctrl+e for time left on estimates • ctrl+x to mark • ctrl+p for priority • \\merge to merge marked • \\shared [split] to time marked items together • \\copy <code> to copy items • \\split to split • \\carry [title] to move unfinished items on • \\est <duration> to estimate • \\goal <duration> a day • \\reset to clear timers • \\spent <duration> to log time • \\pomo to focus • \\ref <url> to link • \\note <text> to annotate • \\desc to describe the task • ctrl+r to open link • \\skip to skip • \\board to toggle the board • \\compact for one line • \\f to filter • \\b to batch add • \\tpl <name> [text] for templates • ctrl+b or \\i to capture • ctrl+y to copy • \\share to copy as text • F5 to refresh • tab to show IDs • ctrl+n to number, alt+digit to jump • ctrl+q to hide timers • F1 for help • ctrl+h to hide hints • esc to go back • \\d to delete • \\q to quit"
+)
+
+func (m model) hints() string {
+	if m.selectedTaskID == 0 {
+		return taskHints
+	}
+	return itemHints
+}
+
+func (m model) footer() string {
+	if m.hideFooter {
+		return ""
+	}
+	hints := itemFooter
+	if m.selectedTaskID == 0 {
+		hints = taskFooter
+	}
+	return "\n\n" + m.live.pace + "\n" + hints
+}
+
+func (m model) updateHelp(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, m.quit()
+	}
+	m.help = false
+	return m, nil
+}
+
+func (m model) viewHelp(b *strings.Builder) {
+	b.WriteString("Keys and commands\n\n")
+	for _, hint := range strings.Split(m.hints(), " • ") {
+		b.WriteString("  " + hint + "\n")
+	}
+	b.WriteString("\nColors: " + colors.String() + "\n")
+	b.WriteString("\nany key to go back")
+}
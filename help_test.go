@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		nav       bool
+		keys      []any
+		wantInput string
+		wantHelp  bool
+	}{
+		{"question mark is typed", false, []any{"? why"}, "? why", false},
+		{"f1 opens help", false, []any{tea.KeyF1}, "", true},
+		{"f1 opens help over text", false, []any{"why", tea.KeyF1}, "why", true},
+		{"question mark opens help in nav mode", true, []any{"?"}, "", true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.setNavMode(tt.nav)
+		m = press(m, tt.keys...)
+		if got := m.input.Value(); got != tt.wantInput {
+			t.Errorf("%s: input = %q, want %q", tt.name, got, tt.wantInput)
+		}
+		if m.help != tt.wantHelp {
+			t.Errorf("%s: help = %v, want %v", tt.name, m.help, tt.wantHelp)
+		}
+	}
+}
+
+func TestFooterIsShort(t *testing.T) {
+	m := newTestModel(t)
+	id, err := m.store.CreateTask("Task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reloadTasks()
+	for _, open := range []int64{0, id} {
+		if open != 0 {
+			m.openTask(open)
+		}
+		hints := m.footer()[strings.LastIndex(m.footer(), "\n")+1:]
+		if n := len([]rune(hints)); n > 100 {
+			t.Errorf("footer hints are %d characters, want at most 100: %q", n, hints)
+		}
+		if !strings.Contains(hints, "F1") {
+			t.Errorf("footer hints %q do not point at F1", hints)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := openDB(memoryDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := setupDB(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestModel(t *testing.T) model {
+	t.Helper()
+	return newModel(newStore(newTestDB(t)))
+}
+
+// press feeds keys to the model; plain strings are typed rune by rune.
+func press(m model, keys ...any) model {
+	for _, k := range keys {
+		var msgs []tea.KeyMsg
+		switch k := k.(type) {
+		case tea.KeyType:
+			msgs = []tea.KeyMsg{{Type: k}}
+		case tea.KeyMsg:
+			msgs = []tea.KeyMsg{k}
+		case string:
+			for _, r := range k {
+				if r == ' ' {
+					msgs = append(msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}})
+				} else {
+					msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+				}
+			}
+		}
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(model)
+		}
+	}
+	return m
+}
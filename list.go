@@ -0,0 +1,32 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+)
+
+func runList(db *sql.DB, w io.Writer, code string) error {
+	if code == "" {
+		for _, t := range loadTasks(db) {
+			done, total := taskProgress(db, t.ID)
+			fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\n", t.Code, statusMarker(t.Status), done, total, t.Title)
+		}
+		return nil
+	}
+
+	t, err := resolveTask(db, command{Task: code})
+	if err != nil {
+		return fmt.Errorf("%s: %w", code, err)
+	}
+	now := time.Now()
+	for _, it := range loadItems(db, t.ID) {
+		text := it.Text
+		if it.Priority {
+			text = "! " + text
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", statusMarker(it.Status), formatDuration(it.elapsed(now)), text)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunListUsesDisplayDurations(t *testing.T) {
+	db := newTestDB(t)
+	id, _ := createTask(db, "Long")
+	now := time.Now()
+	saveItem(db, item{TaskID: id, Text: "marathon", Status: Done, CreatedAt: now, CheckedAt: &now, FrozenDuration: 49*time.Hour + 30*time.Minute})
+	var out bytes.Buffer
+	if err := runList(db, &out, "T01"); err != nil {
+		t.Fatal(err)
+	}
+	want := formatDuration(49*time.Hour + 30*time.Minute)
+	if !strings.Contains(out.String(), "\t"+want+"\t") {
+		t.Errorf("list output %q does not show %q", out.String(), want)
+	}
+}
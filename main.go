@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	_ "modernc.org/sqlite"
 
@@ -19,23 +26,68 @@ const (
 	NotStarted itemStatus = iota
 	Started
 	Done
+	Skipped
 )
 
+// closed reports whether an item needs no more work.
+func (s itemStatus) closed() bool {
+	return s == Done || s == Skipped
+}
+
+type itemFilter int
+
+const (
+	showAll itemFilter = iota
+	hideDone
+	onlyDone
+)
+
+func (f itemFilter) String() string {
+	return map[itemFilter]string{showAll: "all", hideDone: "hiding done", onlyDone: "only done"}[f]
+}
+
+func filterItems(items []item, f itemFilter) []item {
+	if f == showAll {
+		return items
+	}
+	filtered := []item{}
+	for _, it := range items {
+		if it.Status.closed() == (f == onlyDone) {
+			filtered = append(filtered, it)
+		}
+	}
+	return filtered
+}
+
 type task struct {
-	ID     int64
-	Code   string
-	Title  string
-	Status itemStatus
+	ID     int64      `json:"id"`
+	Code   string     `json:"code"`
+	Title  string     `json:"title"`
+	Status itemStatus `json:"status"`
+	Due    *time.Time `json:"due"`
+	Ref    string     `json:"ref"`
+
+	Description  string        `json:"description"`
+	DailyGoal    time.Duration `json:"daily_goal"`
+	LastActivity *time.Time    `json:"last_activity_at"`
 }
 
 type item struct {
-	ID             int64
-	TaskID         int64
-	Text           string
-	Status         itemStatus
-	CreatedAt      time.Time
-	CheckedAt      *time.Time
-	FrozenDuration time.Duration
+	ID             int64         `json:"id"`
+	TaskID         int64         `json:"task_id"`
+	Text           string        `json:"text"`
+	Status         itemStatus    `json:"status"`
+	CreatedAt      time.Time     `json:"created_at"`
+	StartedAt      time.Time     `json:"started_at"`
+	CheckedAt      *time.Time    `json:"checked_at"`
+	FrozenDuration time.Duration `json:"frozen_duration"`
+	ClockedOut     bool          `json:"clocked_out"`
+	Position       int           `json:"position"`
+	Estimate       time.Duration `json:"estimate"`
+	Priority       bool          `json:"priority"`
+	Ref            string        `json:"ref"`
+	Pomodoros      int           `json:"pomodoros"`
+	Note           string        `json:"note"`
 }
 
 type model struct {
@@ -46,9 +98,50 @@ type model struct {
 	cursor         int
 	input          textinput.Model
 	viewportHeight int
+	width          int
 	paused         bool
 	pausedAt       time.Time
-	db             *sql.DB
+	batchAdd       bool
+	showIDs        bool
+	itemFilter     itemFilter
+	confirm        *confirmation
+	prompt         *prompt
+	marked         map[int64]bool
+	alerted        map[int64]bool
+	dashboard      bool
+	taskSort       taskSort
+	sortDesc       bool
+	taskItems      map[int64][]item
+	trash          *trashView
+	heatmap        *heatmapView
+	datePicker     *datePicker
+	stats          *stats
+	report         *report
+	numbered       bool
+	hideTimers     bool
+	hideFooter     bool
+	help           bool
+	navMode        bool
+	pomodoro       *pomodoro
+	shared         *sharedTimer
+	session        session
+	detailID       int64
+	grab           *grab
+	manualOrder    bool
+	compact        bool
+	grouped        bool
+	board          bool
+	clockTimes     bool
+	remaining      bool
+	jumpBuf        string
+	jumpSeq        int
+	backupPrefix   string
+	live           live
+	status         string
+	statusSeq      int
+	saveSeq        int
+	cfg            config
+	store          Storage
 }
 
 type tickMsg time.Time
@@ -59,73 +152,636 @@ func tick() tea.Cmd {
 	})
 }
 
+func statusMarker(s itemStatus) string {
+	return map[itemStatus]string{NotStarted: "[ ]", Started: "[>]", Done: "[x]", Skipped: "[-]"}[s]
+}
+
+func (it item) elapsed(now time.Time) time.Duration {
+	if it.Status == Started && !it.ClockedOut {
+		return now.Sub(it.StartedAt)
+	}
+	return it.FrozenDuration
+}
+
+const (
+	oneDay         = 24 * time.Hour
+	maxShownDays   = 99
+	longRunningFor = oneDay
+)
+
+// displayRounding is the granularity of formatted durations, set from the
+// duration_rounding setting whenever the config is loaded.
+var displayRounding = time.Second
+
+// formatDuration keeps Go's "1h2m3s" form under a day and switches to whole
+// days and hours beyond that, so a forgotten timer cannot widen the row.
+func formatDuration(d time.Duration) string {
+	if d < oneDay {
+		return d.Round(displayRounding).String()
+	}
+	days, hours := int(d/oneDay), int(d%oneDay/time.Hour)
+	if days > maxShownDays {
+		return fmt.Sprintf("%dd+", maxShownDays)
+	}
+	if hours == 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+func (it item) workedOn(day time.Time) bool {
+	if it.Status == NotStarted || it.Status == Skipped {
+		return false
+	}
+	today, loc := startOfDay(day), day.Location()
+	if it.CheckedAt != nil && startOfDay(it.CheckedAt.In(loc)).Equal(today) {
+		return true
+	}
+	return startOfDay(it.StartedAt.In(loc)).Equal(today)
+}
+
+func clockTime(t, now time.Time) string {
+	if startOfDay(t).Equal(startOfDay(now)) {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan 2 15:04")
+}
+
+func clockTimes(it item, now time.Time) string {
+	switch {
+	case it.Status == Done && it.CheckedAt != nil:
+		return clockTime(it.StartedAt, now) + "–" + clockTime(*it.CheckedAt, now)
+	case it.Status == Started:
+		return "since " + clockTime(it.StartedAt, now)
+	}
+	return "not started"
+}
+
+// countDoneTime is whether task totals include the time of Done items, set
+// from the count_done_time setting whenever the config is loaded. Reports
+// follow it too.
+var countDoneTime = true
+
+func totalDuration(items []item, now time.Time) time.Duration {
+	var total time.Duration
+	for _, it := range items {
+		if it.Status != Skipped && (countDoneTime || it.Status != Done) {
+			total += it.elapsed(now)
+		}
+	}
+	return total
+}
+
+// countDone counts done items out of those that still count, leaving
+// skipped items out of both numbers.
+func countDone(items []item) (done, total int) {
+	for _, it := range items {
+		switch it.Status {
+		case Done:
+			done++
+		case Skipped:
+			continue
+		}
+		total++
+	}
+	return done, total
+}
+
 func ptr(t time.Time) *time.Time {
 	return &t
 }
 
-func openDB() (*sql.DB, error) {
-	return sql.Open("sqlite", "./checklist.db")
+const memoryDB = ":memory:"
+
+// openDB opens the database at path, or a private in-memory database when
+// path is ":memory:". An in-memory database lives only as long as its single
+// connection, so the pool is capped at one.
+func openDB(path string) (*sql.DB, error) {
+	if path == memoryDB {
+		db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
+		return db, nil
+	}
+	_, journalErr := os.Stat(path + "-journal")
+	_, walErr := os.Stat(path + "-wal")
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(1000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	if err := waitForDB(db, lockTimeout); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// A journal or WAL left behind by a crash is rolled back or replayed by
+	// SQLite itself on first access; check the result rather than touching it.
+	if journalErr == nil || walErr == nil {
+		var result string
+		if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%s: integrity check after crash recovery could not run: %w", path, err)
+		}
+		if result != "ok" {
+			db.Close()
+			return nil, fmt.Errorf("%s: integrity check failed after crash recovery: %s", path, result)
+		}
+	}
+	return db, nil
 }
 
-func loadTasks(db *sql.DB) []task {
+const lockTimeout = 15 * time.Second
+
+// waitForDB takes and releases a write lock so a database held by another
+// process is detected at startup instead of on the first ignored write.
+func waitForDB(db *sql.DB, timeout time.Duration) error {
+	ctx := context.Background()
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryWriteLock(ctx, db)
+		if err == nil {
+			return nil
+		}
+		if !isBusy(err) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("database is locked by another process, close it and try again")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func tryWriteLock(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK")
+	return err
+}
+
+func isBusy(err error) bool {
+	if errors.Is(err, ErrBusy) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "database is locked")
+}
+
+func setupDB(db *sql.DB) error {
+	initSchema(db)
+	return migrate(db)
+}
+
+func loadTasks(db querier) []task {
 	tasks := []task{}
-	rows, _ := db.Query("SELECT id, code, title, status FROM tasks")
+	rows, err := queryDB(db, "SELECT id, code, title, status, due_at, last_activity_at, ref, description, daily_goal FROM tasks WHERE deleted_at = '' AND archived_at = ''")
+	if err != nil {
+		return tasks
+	}
 	defer rows.Close()
 	for rows.Next() {
 		var t task
-		rows.Scan(&t.ID, &t.Code, &t.Title, &t.Status)
+		var dueAt, activityAt string
+		rows.Scan(&t.ID, &t.Code, &t.Title, &t.Status, &dueAt, &activityAt, &t.Ref, &t.Description, &t.DailyGoal)
+		if dueAt != "" {
+			d, _ := parseStamp(dueAt)
+			t.Due = &d
+		}
+		if activityAt != "" {
+			a, _ := parseStamp(activityAt)
+			t.LastActivity = &a
+		}
 		tasks = append(tasks, t)
 	}
 	return tasks
 }
 
-func loadItems(db *sql.DB, taskID int64) []item {
-	items := []item{}
-	rows, _ := db.Query("SELECT id, task_id, text, status, created_at, checked_at, frozen_duration FROM items WHERE task_id = ?", taskID)
+const itemColumns = "id, task_id, text, status, created_at, checked_at, frozen_duration, clocked_out, position, estimate, priority, started_at, ref, pomodoros, note"
+
+func queryItems(db querier, where string, args ...any) []item {
+	rows, err := queryDB(db, "SELECT "+itemColumns+" FROM items "+where, args...)
+	if err != nil {
+		return []item{}
+	}
 	defer rows.Close()
+	return scanItems(rows)
+}
+
+func scanItems(rows *dbRows) []item {
+	items := []item{}
 	for rows.Next() {
-		var it item
-		var createdAt, checkedAtStr string
-		rows.Scan(&it.ID, &it.TaskID, &it.Text, &it.Status, &createdAt, &checkedAtStr, &it.FrozenDuration)
-		it.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-		if checkedAtStr != "" {
-			t, _ := time.Parse(time.RFC3339, checkedAtStr)
-			it.CheckedAt = &t
+		if it, err := scanItem(rows); err == nil {
+			items = append(items, it)
 		}
-		items = append(items, it)
 	}
 	return items
 }
 
-func saveTask(db *sql.DB, code, title string) {
-	db.Exec("INSERT INTO tasks (code, title, status) VALUES (?, ?, ?)", code, title, NotStarted)
+// scanItem reads the itemColumns of the current row, followed by any extra
+// columns the query selected after them.
+func scanItem(rows *dbRows, extra ...any) (item, error) {
+	var it item
+	var text, createdAt, checkedAt, startedAt, ref, note sql.NullString
+	var taskID, status, frozen, position, estimate, pomodoros sql.NullInt64
+	var clockedOut, priority sql.NullBool
+	dest := append([]any{&it.ID, &taskID, &text, &status, &createdAt, &checkedAt, &frozen, &clockedOut, &position, &estimate, &priority, &startedAt, &ref, &pomodoros, &note}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return it, err
+	}
+	it.TaskID = taskID.Int64
+	it.Text = text.String
+	it.Status = itemStatus(status.Int64)
+	it.FrozenDuration = time.Duration(frozen.Int64)
+	it.ClockedOut = clockedOut.Bool
+	it.Position = int(position.Int64)
+	it.Estimate = time.Duration(estimate.Int64)
+	it.Priority = priority.Bool
+	it.Ref = ref.String
+	it.Pomodoros = int(pomodoros.Int64)
+	it.Note = note.String
+	it.CreatedAt, _ = parseStamp(createdAt.String)
+	it.StartedAt, _ = parseStamp(startedAt.String)
+	if startedAt.String == "" {
+		it.StartedAt = it.CreatedAt
+	}
+	if checkedAt.Valid && checkedAt.String != "" {
+		t, _ := parseStamp(checkedAt.String)
+		it.CheckedAt = &t
+	}
+	return it, nil
+}
+
+// itemWithTask is an item as shown in views spanning several tasks, where
+// its text alone can be ambiguous.
+type itemWithTask struct {
+	item
+	TaskCode  string `json:"task_code"`
+	TaskTitle string `json:"task_title"`
+}
+
+func (it itemWithTask) String() string {
+	return it.TaskCode + ": " + it.Text
+}
+
+func loadAllItemsWithTask(db querier) ([]itemWithTask, error) {
+	return queryItemsWithTask(db, "")
+}
+
+// queryItemsWithTask loads live items of live tasks; where adds conditions
+// on the item columns, which the query names i.
+func queryItemsWithTask(db querier, where string, args ...any) ([]itemWithTask, error) {
+	rows, err := queryDB(db, `SELECT i.*, t.code, t.title
+		FROM (SELECT `+itemColumns+` FROM items WHERE deleted_at = '') i
+		JOIN tasks t ON t.id = i.task_id
+		WHERE t.deleted_at = '' `+where+`
+		ORDER BY t.id, i.position, i.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []itemWithTask{}
+	for rows.Next() {
+		var it itemWithTask
+		if it.item, err = scanItem(rows, &it.TaskCode, &it.TaskTitle); err == nil {
+			items = append(items, it)
+		}
+	}
+	return items, rows.Err()
+}
+
+func loadItems(db querier, taskID int64) []item {
+	return queryItems(db, "WHERE task_id = ? AND deleted_at = '' ORDER BY position, id", taskID)
 }
 
-func deleteTask(db *sql.DB, taskID int64) {
-	db.Exec("DELETE FROM items WHERE task_id = ?", taskID)
-	db.Exec("DELETE FROM tasks WHERE id = ?", taskID)
+func loadRunningItems(db querier) []item {
+	return queryItems(db, "WHERE status = ? AND clocked_out = 0 AND deleted_at = '' AND task_id IN (SELECT id FROM tasks WHERE deleted_at = '') ORDER BY task_id, position, id", Started)
 }
 
-func deleteItem(db *sql.DB, itemID int64) {
-	db.Exec("DELETE FROM items WHERE id = ?", itemID)
+func setItemEstimate(db querier, itemID int64, d time.Duration) {
+	execDB(db, "UPDATE items SET estimate = ? WHERE id = ?", d, itemID)
 }
 
-func saveItem(db *sql.DB, it item) {
+func setItemNote(db querier, itemID int64, note string) {
+	execDB(db, "UPDATE items SET note = ? WHERE id = ?", note, itemID)
+}
+
+func setItemPriority(db querier, itemID int64, high bool) {
+	execDB(db, "UPDATE items SET priority = ? WHERE id = ?", high, itemID)
+}
+
+// nextTaskCode numbers on from every task ever created, deleted and
+// archived ones included, skipping codes that are already in use.
+func nextTaskCode(db querier) string {
+	var n int
+	queryRowDB(db, "SELECT COUNT(*) FROM tasks").Scan(&n)
+	for {
+		n++
+		if code := fmt.Sprintf("T%02d", n); !codeTaken(db, code) {
+			return code
+		}
+	}
+}
+
+func saveTask(db querier, code, title string) int64 {
+	res, err := execDB(db, "INSERT INTO tasks (code, title, status) VALUES (?, ?, ?)", code, title, NotStarted)
+	if err != nil {
+		return 0
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+var taskCodeRe = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s+(.*)$`)
+
+// parseTaskInput splits "CODE: title" into its parts. Without a code prefix
+// the whole input is the title and code is empty.
+func parseTaskInput(s string) (code, title string) {
+	s = strings.TrimSpace(s)
+	if m := taskCodeRe.FindStringSubmatch(s); m != nil {
+		return m[1], strings.TrimSpace(m[2])
+	}
+	return "", s
+}
+
+func codeTaken(db querier, code string) bool {
+	var n int
+	queryRowDB(db, "SELECT COUNT(*) FROM tasks WHERE code = ? COLLATE NOCASE", code).Scan(&n)
+	return n > 0
+}
+
+// updateTask renames a task from "CODE: title", or from a bare title that
+// keeps the current code.
+func updateTask(db querier, taskID int64, input string) error {
+	code, title := parseTaskInput(input)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if code != "" {
+		var current string
+		queryRowDB(db, "SELECT code FROM tasks WHERE id = ?", taskID).Scan(&current)
+		if !strings.EqualFold(code, current) && codeTaken(db, code) {
+			return fmt.Errorf("code %s is %w", code, ErrConflict)
+		}
+		execDB(db, "UPDATE tasks SET code = ? WHERE id = ?", code, taskID)
+	}
+	setTaskTitle(db, taskID, title)
+	return nil
+}
+
+func createTask(db querier, input string) (int64, error) {
+	code, title := parseTaskInput(input)
+	if title == "" {
+		return 0, fmt.Errorf("title is required")
+	}
+	if code == "" {
+		code = nextTaskCode(db)
+	} else if codeTaken(db, code) {
+		return 0, fmt.Errorf("code %s is %w", code, ErrConflict)
+	}
+	id := saveTask(db, code, title)
+	if id == 0 {
+		return 0, fmt.Errorf("could not save task")
+	}
+	return id, nil
+}
+
+func taskExists(db querier, taskID int64) bool {
+	var n int
+	queryRowDB(db, "SELECT COUNT(*) FROM tasks WHERE id = ? AND deleted_at = ''", taskID).Scan(&n)
+	return n > 0
+}
+
+const inboxCode = "INBOX"
+
+// reservedTask returns the task with the given code, bringing it back from
+// the trash or the archive if that is where it is, and creates it only
+// when no task has the code at all, so the code stays unique.
+func reservedTask(db querier, code, title string) (int64, error) {
+	var id int64
+	err := queryRowDB(db, `SELECT id FROM tasks WHERE code = ? COLLATE NOCASE
+		ORDER BY deleted_at != '', archived_at != '', id LIMIT 1`, code).Scan(&id)
+	if err != nil {
+		return createTask(db, code+": "+title)
+	}
+	if _, err := execDB(db, "UPDATE tasks SET deleted_at = '', archived_at = '' WHERE id = ?", id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func inboxTaskID(db querier) int64 {
+	id, _ := reservedTask(db, inboxCode, "Inbox")
+	return id
+}
+
+func setTaskDue(db querier, taskID int64, due *time.Time) {
+	var dueAt string
+	if due != nil {
+		dueAt = formatStamp(*due)
+	}
+	execDB(db, "UPDATE tasks SET due_at = ? WHERE id = ?", dueAt, taskID)
+}
+
+func setTaskTitle(db querier, taskID int64, title string) {
+	execDB(db, "UPDATE tasks SET title = ? WHERE id = ?", title, taskID)
+}
+
+// setTaskStatus overrides the status derived from the items until the next
+// item change runs updateTaskStatus again.
+func setTaskStatus(db querier, taskID int64, s itemStatus) {
+	execDB(db, "UPDATE tasks SET status = ?, last_activity_at = ? WHERE id = ?", s, formatStamp(time.Now()), taskID)
+}
+
+func setTaskGoal(db querier, taskID int64, d time.Duration) {
+	execDB(db, "UPDATE tasks SET daily_goal = ? WHERE id = ?", d, taskID)
+}
+
+func setTaskDescription(db querier, taskID int64, desc string) {
+	execDB(db, "UPDATE tasks SET description = ? WHERE id = ?", desc, taskID)
+}
+
+func setTaskRef(db querier, taskID int64, ref string) {
+	execDB(db, "UPDATE tasks SET ref = ? WHERE id = ?", ref, taskID)
+}
+
+func setItemRef(db querier, itemID int64, ref string) {
+	execDB(db, "UPDATE items SET ref = ? WHERE id = ?", ref, itemID)
+}
+
+func deleteTask(db querier, taskID int64) error {
+	_, err := execDB(db, "UPDATE tasks SET deleted_at = ? WHERE id = ?", formatStamp(time.Now()), taskID)
+	return err
+}
+
+func deleteItem(db querier, itemID int64) error {
+	_, err := execDB(db, "UPDATE items SET deleted_at = ? WHERE id = ?", formatStamp(time.Now()), itemID)
+	return err
+}
+
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func saveItem(db querier, it item) int64 {
+	var checkedAtStr string
+	if it.CheckedAt != nil {
+		checkedAtStr = formatStamp(*it.CheckedAt)
+	}
+	if it.StartedAt.IsZero() {
+		it.StartedAt = it.CreatedAt
+	}
+	if it.Position == 0 {
+		queryRowDB(db, "SELECT COALESCE(MAX(position), 0) + 1 FROM items WHERE task_id = ?", it.TaskID).Scan(&it.Position)
+	}
+	res, err := execDB(db, `INSERT INTO items (task_id, text, status, created_at, checked_at, frozen_duration, duration_seconds, position, priority, started_at, ref, estimate, note, pomodoros, clocked_out) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		it.TaskID, it.Text, it.Status, formatStamp(it.CreatedAt), checkedAtStr, it.FrozenDuration, durationSeconds(it.FrozenDuration), it.Position, it.Priority, formatStamp(it.StartedAt), it.Ref, it.Estimate, it.Note, it.Pomodoros, it.ClockedOut)
+	if err != nil {
+		return 0
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+func copyItems(db *sql.DB, fromTaskID, toTaskID int64) (int, error) {
+	items := loadItems(db, fromTaskID)
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	for _, it := range items {
+		if saveItem(tx, item{TaskID: toTaskID, Text: it.Text, Status: NotStarted, CreatedAt: now, Priority: it.Priority, Ref: it.Ref}) == 0 {
+			tx.Rollback()
+			return 0, fmt.Errorf("could not copy %q", it.Text)
+		}
+	}
+	return len(items), tx.Commit()
+}
+
+func insertItemAfter(db querier, after item, it item) int64 {
+	execDB(db, "UPDATE items SET position = position + 1 WHERE task_id = ? AND position > ?", after.TaskID, after.Position)
+	it.TaskID = after.TaskID
+	it.Position = after.Position + 1
+	return saveItem(db, it)
+}
+
+func updateItemText(db querier, itemID int64, text string) {
+	execDB(db, "UPDATE items SET text = ? WHERE id = ?", text, itemID)
+}
+
+func cycleStatus(i *item, now time.Time) {
+	switch i.Status {
+	case NotStarted:
+		i.Status = Started
+		i.StartedAt = now.Add(-i.FrozenDuration)
+	case Started:
+		i.FrozenDuration = i.elapsed(now)
+		i.Status = Done
+		i.CheckedAt = &now
+	case Done, Skipped:
+		i.Status = NotStarted
+	}
+	i.ClockedOut = false
+}
+
+// completeItem marks an item Done from any open state. A not-started item
+// is completed with no time on it.
+func completeItem(i *item, now time.Time) {
+	if i.Status == NotStarted {
+		i.Status = Started
+		i.StartedAt = now.Add(-i.FrozenDuration)
+	}
+	cycleStatus(i, now)
+}
+
+func toggleSkip(i *item, now time.Time) {
+	if i.Status == Skipped {
+		i.Status = NotStarted
+		return
+	}
+	i.FrozenDuration = i.elapsed(now)
+	i.Status = Skipped
+	i.CheckedAt = nil
+	i.ClockedOut = false
+}
+
+func toggleClock(i *item, now time.Time) {
+	if i.Status == Started && !i.ClockedOut {
+		i.FrozenDuration = now.Sub(i.StartedAt)
+		i.ClockedOut = true
+		return
+	}
+	i.Status = Started
+	i.CheckedAt = nil
+	i.StartedAt = now.Add(-i.FrozenDuration)
+	i.ClockedOut = false
+}
+
+func reopenItem(i *item, now time.Time) {
+	i.Status = Started
+	i.CheckedAt = nil
+	i.StartedAt = now.Add(-i.FrozenDuration)
+	i.ClockedOut = false
+}
+
+func logSpent(i *item, d time.Duration, now time.Time) {
+	if i.Status != Done || i.CheckedAt == nil {
+		i.Status = Done
+		i.CheckedAt = &now
+	}
+	i.FrozenDuration = d
+	i.StartedAt = i.CheckedAt.Add(-d)
+	i.ClockedOut = false
+}
+
+func durationSeconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+func saveItemStatus(db querier, it item) {
 	var checkedAtStr string
 	if it.CheckedAt != nil {
-		checkedAtStr = it.CheckedAt.Format(time.RFC3339)
+		checkedAtStr = formatStamp(*it.CheckedAt)
 	}
-	db.Exec(`INSERT INTO items (task_id, text, status, created_at, checked_at, frozen_duration) VALUES (?, ?, ?, ?, ?, ?)`,
-		it.TaskID, it.Text, it.Status, it.CreatedAt.Format(time.RFC3339), checkedAtStr, it.FrozenDuration)
+	execDB(db, "UPDATE items SET status = ?, started_at = ?, checked_at = ?, frozen_duration = ?, duration_seconds = ?, clocked_out = ? WHERE id = ?",
+		it.Status, formatStamp(it.StartedAt), checkedAtStr, it.FrozenDuration, durationSeconds(it.FrozenDuration), it.ClockedOut, it.ID)
+}
+
+func taskProgress(db querier, taskID int64) (done, total int) {
+	queryRowDB(db, "SELECT COUNT(*) FROM items WHERE task_id = ? AND status != ? AND deleted_at = ''", taskID, Skipped).Scan(&total)
+	queryRowDB(db, "SELECT COUNT(*) FROM items WHERE task_id = ? AND status = ? AND deleted_at = ''", taskID, Done).Scan(&done)
+	return done, total
+}
+
+func allDone(db querier, taskID int64) bool {
+	done, total := taskProgress(db, taskID)
+	return total > 0 && done == total
 }
 
-func updateTaskStatus(db *sql.DB, taskID int64) {
+// updateTaskStatus derives the task status from its items, records the
+// activity, and reports whether the status changed.
+func updateTaskStatus(db querier, taskID int64) bool {
+	changed := reconcileTaskStatus(db, taskID)
+	execDB(db, "UPDATE tasks SET last_activity_at = ? WHERE id = ?", formatStamp(time.Now()), taskID)
+	return changed
+}
+
+func reconcileTaskStatus(db querier, taskID int64) bool {
 	var total, done, started int
-	row := db.QueryRow("SELECT COUNT(*) FROM items WHERE task_id = ?", taskID)
+	row := queryRowDB(db, "SELECT COUNT(*) FROM items WHERE task_id = ? AND status != ? AND deleted_at = ''", taskID, Skipped)
 	row.Scan(&total)
-	row = db.QueryRow("SELECT COUNT(*) FROM items WHERE task_id = ? AND status = ?", taskID, Done)
+	row = queryRowDB(db, "SELECT COUNT(*) FROM items WHERE task_id = ? AND status = ? AND deleted_at = ''", taskID, Done)
 	row.Scan(&done)
-	row = db.QueryRow("SELECT COUNT(*) FROM items WHERE task_id = ? AND status = ?", taskID, Started)
+	row = queryRowDB(db, "SELECT COUNT(*) FROM items WHERE task_id = ? AND status = ? AND deleted_at = ''", taskID, Started)
 	row.Scan(&started)
 
 	var newStatus itemStatus
@@ -136,15 +792,25 @@ func updateTaskStatus(db *sql.DB, taskID int64) {
 	} else {
 		newStatus = NotStarted
 	}
-	db.Exec("UPDATE tasks SET status = ? WHERE id = ?", newStatus, taskID)
+	var current itemStatus
+	if err := queryRowDB(db, "SELECT status FROM tasks WHERE id = ?", taskID).Scan(&current); err == nil && current == newStatus {
+		return false
+	}
+	execDB(db, "UPDATE tasks SET status = ? WHERE id = ?", newStatus, taskID)
+	return true
 }
 
-func initialModel() model {
-	db, err := openDB()
-	if err != nil {
-		fmt.Println("Failed to open DB:", err)
-		os.Exit(1)
+func fixTaskStatuses(db querier) int {
+	changed := 0
+	for _, t := range loadTasks(db) {
+		if reconcileTaskStatus(db, t.ID) {
+			changed++
+		}
 	}
+	return changed
+}
+
+func initSchema(db *sql.DB) {
 	db.Exec(`CREATE TABLE IF NOT EXISTS tasks (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		code TEXT,
@@ -160,120 +826,337 @@ func initialModel() model {
 		checked_at TEXT,
 		frozen_duration INTEGER
 	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT
+	)`)
+}
+
+func mustOpenDB(path string) *sql.DB {
+	db, err := openDB(path)
+	if err != nil {
+		fmt.Println("Failed to open DB:", err)
+		os.Exit(1)
+	}
+	if err := setupDB(db); err != nil {
+		fmt.Println("Failed to migrate DB:", err)
+		os.Exit(1)
+	}
+	return db
+}
+
+func newModel(store Storage) model {
 	input := textinput.New()
-	input.Placeholder = "Add new task"
+	input.Placeholder = "+title to add a task"
 	input.Focus()
-	return model{
-		tasks: loadTasks(db),
+	m := model{
 		input: input,
-		db:    db,
+		cfg:   loadConfig(store),
+		store: store,
+	}
+	m.backupPrefix = store.BackupPrefix()
+	m.dashboard = m.cfg.Dashboard
+	m.numbered = m.cfg.Numbered
+	m.hideTimers = m.cfg.HideTimers
+	m.hideFooter = m.cfg.HideFooter
+	m.session = newSession(time.Now())
+	m.grouped = m.cfg.GroupByStatus
+	m.setNavMode(m.cfg.Modal)
+	m.taskSort, m.sortDesc = m.cfg.TaskSort, m.cfg.SortDesc
+	m.reloadTasks()
+	m.pausedAt, _, m.paused = store.Pause()
+
+	state := loadViewState(store)
+	if state.TaskID != 0 && store.TaskExists(state.TaskID) {
+		m.selectedTaskID = state.TaskID
+		m.reloadItems()
+		m.input.Placeholder = m.placeholder()
 	}
+	m.cursor = state.Cursor
+	m.clampCursor()
+
+	if m.cfg.CheckOrphans {
+		if orphans, err := store.Orphans(); err == nil && len(orphans) > 0 {
+			m.setStatus(fmt.Sprintf("%d orphaned items found, see \\orphans", len(orphans)))
+		}
+	}
+	m.refreshLive(time.Now())
+	return m
 }
 
 func (m model) Init() tea.Cmd {
-	return tick()
+	return tea.Batch(tick(), m.scheduleBackup(m.cfg.BackupInterval-time.Since(lastBackupAt(m.backupPrefix))))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	prev, prevStatus := m.viewState(), m.statusSeq
+	next, cmd := m.update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok && next.paused {
+		next.holdTimers(time.Now())
+	}
+	next.refreshLive(time.Now())
+	if next.viewState() != prev {
+		cmd = tea.Batch(cmd, next.scheduleSave())
+	}
+	if next.statusSeq != prevStatus && next.status != "" {
+		cmd = tea.Batch(cmd, next.clearStatusLater())
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case saveStateMsg:
+		if msg.seq == m.saveSeq {
+			saveViewState(m.store, m.viewState())
+		}
+		return m, nil
+
+	case jumpResetMsg:
+		if msg.seq == m.jumpSeq {
+			m.jumpBuf = ""
+		}
+		return m, nil
+
+	case clearStatusMsg:
+		if msg.seq == m.statusSeq {
+			m.status = ""
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.viewportHeight = msg.Height - 4
+		m.width = msg.Width
 		return m, nil
 
 	case tickMsg:
-		return m, tick()
+		alert := m.checkOverruns(time.Time(msg))
+		ring := m.tickPomodoro(time.Time(msg))
+		m.tickSession(time.Time(msg))
+		return m, tea.Batch(tick(), alert, ring)
+
+	case backupMsg:
+		m.runBackup(time.Now())
+		return m, m.scheduleBackup(m.cfg.BackupInterval)
 
 	case tea.KeyMsg:
 		input := strings.TrimSpace(m.input.Value())
 
-		if input == "\\q" {
-			return m, tea.Quit
-		}
-
-		if input == "\\r" {
-			if m.selectedTaskID != 0 && len(m.items) > 0 {
-				i := &m.items[m.cursor]
-				now := time.Now()
-				i.Status = Started
-				i.CreatedAt = now
-				i.CheckedAt = nil
-				i.FrozenDuration = 0
-				m.db.Exec("UPDATE items SET status = ?, created_at = ?, checked_at = ?, frozen_duration = ? WHERE id = ?",
-					i.Status,
-					now.Format(time.RFC3339),
-					nil,
-					0,
-					i.ID,
-				)
-				updateTaskStatus(m.db, m.selectedTaskID)
-				m.input.SetValue("")
-				return m, nil
+		if m.confirm != nil {
+			return m.updateConfirm(msg)
+		}
+
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+
+		if m.batchAdd {
+			return m.updateBatchAdd(msg, input)
+		}
+
+		if m.trash != nil {
+			return m.updateTrash(msg, input)
+		}
+
+		if m.heatmap != nil {
+			return m.updateHeatmap(msg)
+		}
+
+		if m.datePicker != nil {
+			return m.updateDatePicker(msg)
+		}
+
+		if m.stats != nil {
+			return m.updateStats(msg)
+		}
+
+		if m.report != nil {
+			return m.updateReport(msg)
+		}
+
+		if m.help {
+			return m.updateHelp(msg)
+		}
+
+		if m.detailID != 0 {
+			return m.updateDetail(msg)
+		}
+
+		if m.grab != nil {
+			return m.updateGrab(msg)
+		}
+
+		if m.compact {
+			return m.updateCompact(msg)
+		}
+
+		if m.navMode {
+			if next, cmd, ok := m.updateNav(msg); ok {
+				return next, cmd
 			}
 		}
 
-		if input == "\\d" {
-			if m.selectedTaskID == 0 && len(m.tasks) > 0 {
-				taskID := m.tasks[m.cursor].ID
-				deleteTask(m.db, taskID)
-				m.tasks = loadTasks(m.db)
-				if m.cursor > 0 {
-					m.cursor--
-				}
-				m.input.SetValue("")
-				return m, nil
-			} else if m.selectedTaskID != 0 && len(m.items) > 0 {
-				itemID := m.items[m.cursor].ID
-				deleteItem(m.db, itemID)
-				m.items = loadItems(m.db, m.selectedTaskID)
-				updateTaskStatus(m.db, m.selectedTaskID)
-				if m.cursor > 0 {
-					m.cursor--
-				}
-				m.input.SetValue("")
-				return m, nil
+		if m.board && m.selectedTaskID != 0 {
+			if next, ok := m.updateBoard(msg, input); ok {
+				return next, nil
 			}
 		}
 
+		// ctrl+h doubles as backspace in some terminals, so only an empty
+		// input, where backspace does nothing, takes it as the toggle.
+		if m.input.Value() == "" && msg.String() == "ctrl+h" {
+			m.hideFooter = !m.hideFooter
+			m.store.SetSetting("hide_footer", strconv.FormatBool(m.hideFooter))
+			return m, nil
+		}
+
+		if msg.String() == "f1" || m.navMode && msg.String() == "?" {
+			m.help = true
+			return m, nil
+		}
+
+		// Brackets are text too, so they step tasks the same way.
+		if key := strings.TrimPrefix(msg.String(), "alt+"); m.selectedTaskID != 0 && (m.navMode || msg.Alt) && (key == "[" || key == "]") {
+			delta := 1
+			if key == "[" {
+				delta = -1
+			}
+			m.stepTask(delta)
+			return m, nil
+		}
+
+		// A bare digit is text while typing, so it only jumps in navigation
+		// mode; alt+digit jumps from anywhere.
+		if m.numbered && (m.navMode || msg.Alt) && msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && unicode.IsDigit(msg.Runes[0]) {
+			return m, m.jumpDigit(msg.Runes[0])
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
 
-		case "enter":
+		case "ctrl+n":
+			m.numbered = !m.numbered
+			m.store.SetSetting("numbered", strconv.FormatBool(m.numbered))
+			return m, nil
+
+		case "ctrl+q":
+			m.hideTimers = !m.hideTimers
+			m.store.SetSetting("hide_timers", strconv.FormatBool(m.hideTimers))
+			return m, nil
+
+		case "ctrl+y":
+			m.copySelection()
+			return m, nil
+
+		case "ctrl+x":
+			m.toggleMark()
+			return m, nil
+
+		case "ctrl+p":
+			m.togglePriority()
+			return m, nil
+
+		case "ctrl+l":
+			m.clockTimes = !m.clockTimes
+			return m, nil
+
+		case "ctrl+e":
+			if m.selectedTaskID != 0 && m.input.Value() == "" {
+				m.remaining = !m.remaining
+				return m, nil
+			}
+
+		case "ctrl+r":
+			m.openRef()
+			return m, nil
+
+		case "ctrl+s":
+			m.focusNext()
+			return m, nil
+
+		case "ctrl+o":
+			m.reopenLastDone()
+			return m, nil
+
+		case "ctrl+g":
 			if m.selectedTaskID == 0 {
-				if len(m.tasks) > 0 && input == "" {
-					m.selectedTaskID = m.tasks[m.cursor].ID
-					m.items = loadItems(m.db, m.selectedTaskID)
-					m.input.Placeholder = "Add new item"
-					m.input.SetValue("")
-					m.cursor = 0
-				} else if input != "" {
-					saveTask(m.db, fmt.Sprintf("T%02d", len(m.tasks)+1), input)
-					m.tasks = loadTasks(m.db)
-					m.input.SetValue("")
-				}
+				m.dashboard = !m.dashboard
+				m.store.SetSetting("dashboard", strconv.FormatBool(m.dashboard))
 			} else {
-				if input != "" {
-					it := item{
-						TaskID:    m.selectedTaskID,
-						Text:      input,
-						Status:    NotStarted,
-						CreatedAt: time.Now(),
+				m.startGrab()
+			}
+			return m, nil
+
+		case "ctrl+b":
+			m.input.SetValue("")
+			m.captureToInbox(strings.TrimSpace(input))
+			return m, nil
+
+		case "ctrl+t":
+			m.toggleClock()
+			return m, nil
+
+		case "f2":
+			m.renameSelected()
+			return m, nil
+
+		case "f5":
+			m.refresh()
+			return m, nil
+
+		case "ctrl+k":
+			if m.selectedTaskID == 0 && m.input.Value() == "" {
+				m.copyCodes("")
+				return m, nil
+			}
+
+		case "ctrl+d":
+			if m.selectedTaskID != 0 && m.input.Value() == "" {
+				m.completeAndAdvance()
+				return m, nil
+			}
+
+		case "tab":
+			m.showIDs = !m.showIDs
+			return m, nil
+
+		case "enter":
+			// A line starting with a backslash is a command and runs when
+			// Enter submits it, never while it is still being typed.
+			if strings.HasPrefix(input, "\\") {
+				return m.runCommand(input)
+			}
+			if m.selectedTaskID == 0 {
+				title, explicit := strings.CutPrefix(input, "+")
+				title = strings.TrimSpace(title)
+				switch {
+				case explicit && title != "":
+					m.addTask(title)
+				case m.cfg.LegacyEnter && input != "":
+					m.addTask(input)
+				case input != "" && !explicit:
+					m.setStatus("Type +" + input + " to add it as a task")
+				case !explicit:
+					if t, ok := m.currentTask(); ok {
+						m.openTask(t.ID)
 					}
-					saveItem(m.db, it)
-					m.items = loadItems(m.db, m.selectedTaskID)
-					updateTaskStatus(m.db, m.selectedTaskID)
-					m.input.SetValue("")
 				}
+			} else if input != "" {
+				m.addItem(input)
+			} else {
+				m.openDetail()
 			}
 
 		case "esc":
-			m.selectedTaskID = 0
-			m.items = nil
-			m.input.Placeholder = "Add new task"
-			m.input.SetValue("")
-			m.tasks = loadTasks(m.db)
+			if m.cfg.Modal && !m.navMode {
+				m.input.SetValue("")
+				m.setNavMode(true)
+				return m, nil
+			}
+			m.closeTask()
 
 		case "up":
 			if m.cursor > 0 {
@@ -288,31 +1171,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case " ":
-			if m.selectedTaskID != 0 && len(m.items) > 0 && strings.TrimSpace(m.input.Value()) == "" {
-				i := &m.items[m.cursor]
-				switch i.Status {
-				case NotStarted:
-					i.Status = Started
-				case Started:
-					i.Status = Done
-					now := time.Now()
-					i.CheckedAt = &now
-					i.FrozenDuration = now.Sub(i.CreatedAt)
-				case Done:
-					i.Status = NotStarted
+			if m.selectedTaskID == 0 && input == "" {
+				m.cycleTaskStatus()
+				return m, nil
+			}
+			if i := m.currentItem(); i != nil && input == "" && !m.taskGone() {
+				if !m.changeItemStatus(i, cycleStatus) && (m.itemFilter != showAll || m.cfg.DoneLast) {
+					m.reloadItems()
 				}
-				m.db.Exec("UPDATE items SET status = ?, checked_at = ?, frozen_duration = ? WHERE id = ?",
-					i.Status,
-					func() string {
-						if i.CheckedAt != nil {
-							return i.CheckedAt.Format(time.RFC3339)
-						}
-						return ""
-					}(),
-					i.FrozenDuration,
-					i.ID,
-				)
-				updateTaskStatus(m.db, m.selectedTaskID)
 			}
 		}
 	}
@@ -325,41 +1191,528 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func mergeItems(items []item, now time.Time) item {
+	merged := item{TaskID: items[0].TaskID, Position: items[0].Position, CreatedAt: items[0].CreatedAt}
+	texts, notes := []string{}, []string{}
+	allDone, anyProgress, running := true, false, false
+	for _, it := range items {
+		texts = append(texts, it.Text)
+		if it.Note != "" {
+			notes = append(notes, it.Note)
+		}
+		switch {
+		case merged.Ref == "":
+			merged.Ref = it.Ref
+		case it.Ref != "" && it.Ref != merged.Ref:
+			notes = append(notes, it.Ref)
+		}
+		merged.Estimate += it.Estimate
+		merged.Pomodoros += it.Pomodoros
+		running = running || it.Status == Started && !it.ClockedOut
+		merged.FrozenDuration += it.elapsed(now)
+		if it.CreatedAt.Before(merged.CreatedAt) {
+			merged.CreatedAt = it.CreatedAt
+		}
+		if it.CheckedAt != nil && (merged.CheckedAt == nil || it.CheckedAt.After(*merged.CheckedAt)) {
+			merged.CheckedAt = it.CheckedAt
+		}
+		allDone = allDone && it.Status == Done
+		anyProgress = anyProgress || it.Status != NotStarted
+		merged.Priority = merged.Priority || it.Priority
+	}
+	merged.Text = strings.Join(texts, "; ")
+	merged.Note = strings.Join(notes, "\n")
+
+	switch {
+	case allDone:
+		merged.Status = Done
+	case anyProgress:
+		merged.Status = Started
+		merged.CheckedAt = nil
+		merged.StartedAt = now.Add(-merged.FrozenDuration)
+		merged.ClockedOut = !running
+	default:
+		merged.Status = NotStarted
+		merged.CheckedAt = nil
+	}
+	return merged
+}
+
+func sortPriorityFirst(items []item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Priority && !items[j].Priority
+	})
+}
+
+func sortDoneLast(items []item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return !items[i].Status.closed() && items[j].Status.closed()
+	})
+}
+
+func (m *model) reloadItems() {
+	items := m.store.Items(m.selectedTaskID)
+	if m.cfg.PriorityFirst && !m.manualOrder {
+		sortPriorityFirst(items)
+	}
+	if m.cfg.DoneLast && !m.manualOrder {
+		sortDoneLast(items)
+	}
+	m.items = filterItems(items, m.itemFilter)
+	m.clampCursor()
+}
+
+func (m *model) currentItem() *item {
+	if m.selectedTaskID == 0 || m.cursor < 0 || m.cursor >= len(m.items) {
+		return nil
+	}
+	return &m.items[m.cursor]
+}
+
+func (m model) currentTask() (task, bool) {
+	if m.selectedTaskID != 0 || m.cursor < 0 || m.cursor >= len(m.tasks) {
+		return task{}, false
+	}
+	return m.tasks[m.cursor], true
+}
+
+func (m model) openedTask() (task, bool) {
+	return m.taskByID(m.selectedTaskID)
+}
+
+func (m model) taskByID(id int64) (task, bool) {
+	for _, t := range m.tasks {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return task{}, false
+}
+
+func (m *model) clampCursor() {
+	n := len(m.tasks)
+	if m.selectedTaskID != 0 {
+		n = len(m.items)
+	}
+	m.cursor = max(min(m.cursor, n-1), 0)
+}
+
+func (m *model) openTask(id int64) {
+	m.selectedTaskID = id
+	m.marked = nil
+	m.manualOrder = false
+	m.cursor = 0
+	m.reloadItems()
+	m.exitEditMode()
+}
+
+func (m *model) addTask(input string) {
+	if _, err := m.store.CreateTask(input); err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+	m.reloadTasks()
+	m.warnTaskCap()
+	m.input.SetValue("")
+}
+
+func (m *model) closeTask() {
+	prev := m.selectedTaskID
+	m.selectedTaskID = 0
+	m.manualOrder = false
+	m.items = nil
+	m.marked = nil
+	m.exitEditMode()
+	m.reloadTasks()
+	for i, t := range m.tasks {
+		if t.ID == prev {
+			m.cursor = i
+		}
+	}
+	m.clampCursor()
+}
+
+func (m *model) taskGone() bool {
+	if m.selectedTaskID == 0 || m.store.TaskExists(m.selectedTaskID) {
+		return false
+	}
+	m.closeTask()
+	m.setStatus("The selected task no longer exists")
+	return true
+}
+
+// refresh rereads everything on screen, for when another instance or a
+// script has changed the database underneath.
+func (m *model) refresh() {
+	if m.taskGone() {
+		m.reloadTasks()
+		return
+	}
+	if m.selectedTaskID != 0 {
+		m.store.ReconcileTaskStatus(m.selectedTaskID)
+		m.reloadItems()
+	}
+	m.reloadTasks()
+	m.setStatus("Refreshed")
+}
+
+func (m *model) addItem(text string) {
+	if m.taskGone() {
+		return
+	}
+	it := item{
+		TaskID:    m.selectedTaskID,
+		Text:      text,
+		Status:    NotStarted,
+		CreatedAt: time.Now(),
+	}
+	m.store.SaveItem(it)
+	m.reloadItems()
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.warnItemCap(m.selectedTaskID)
+	m.input.SetValue("")
+}
+
+func (m model) placeholder() string {
+	switch {
+	case m.prompt != nil:
+		return m.prompt.label
+	case m.batchAdd && m.selectedTaskID == 0:
+		return "Add tasks (esc to finish)"
+	case m.batchAdd:
+		return "Add items (esc to finish)"
+	case m.selectedTaskID == 0:
+		return "+title to add a task"
+	}
+	return "Add new item"
+}
+
+func (m model) updateBatchAdd(msg tea.KeyMsg, input string) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+
+	case "esc":
+		m.batchAdd = false
+		m.exitEditMode()
+		return m, nil
+
+	case "enter":
+		if input != "" {
+			if m.selectedTaskID == 0 {
+				m.addTask(input)
+			} else {
+				m.addItem(input)
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
 func (m model) View() string {
+	if m.compact {
+		return m.live.compact
+	}
 	var b strings.Builder
-	b.WriteString("Checklist:\n\n")
-	if m.selectedTaskID == 0 {
-		for i, t := range m.tasks {
-			cursor := " "
-			if i == m.cursor {
-				cursor = ">"
+	b.WriteString(m.pauseBanner(time.Now()))
+	if m.hideTimers {
+		b.WriteString("Checklist:\n\n")
+	} else {
+		b.WriteString("Checklist:  " + m.session.String() + "\n\n")
+	}
+	b.WriteString(m.pomodoroLine(time.Now()))
+	b.WriteString(m.sharedLine(time.Now()))
+	if m.trash != nil {
+		m.viewTrash(&b)
+	} else if m.heatmap != nil {
+		m.viewHeatmap(&b)
+	} else if m.datePicker != nil {
+		m.viewDatePicker(&b)
+	} else if m.stats != nil {
+		m.viewStats(&b)
+	} else if m.report != nil {
+		m.viewReport(&b)
+	} else if m.help {
+		m.viewHelp(&b)
+	} else if m.detailID != 0 {
+		m.viewDetail(&b)
+	} else if m.selectedTaskID == 0 {
+		if m.taskSort != sortNone {
+			b.WriteString(fmt.Sprintf("(sorted by %s)\n", formatTaskSort(m.taskSort, m.sortDesc)))
+		}
+		if len(m.tasks) == 0 {
+			b.WriteString(m.emptyState("No tasks yet — type +title to add one"))
+		} else if m.dashboard {
+			m.viewDashboard(&b)
+		} else {
+			for i, t := range m.tasks {
+				b.WriteString(m.groupHeader(i))
+				cursor := " "
+				if i == m.cursor {
+					cursor = ">"
+				}
+				activity := ""
+				if m.cfg.ShowActivity {
+					activity = "  " + activityLabel(t.LastActivity, time.Now())
+				}
+				if goal := goalLabel(t, m.taskItems[t.ID], time.Now()); goal != "" {
+					activity += "  " + goal
+				}
+				if m.inlineEditing(i) {
+					b.WriteString(fmt.Sprintf("%s %s %s\n", cursor, statusMarker(t.Status), m.inlineInput()))
+					continue
+				}
+				b.WriteString(fmt.Sprintf("%s %s %s%s%s - %s%s%s\n", cursor, statusMarker(t.Status), m.numberPrefix(i), m.idPrefix(t.ID), t.Code, t.Title, refMarker(t.Ref), activity))
 			}
-			statusStr := map[itemStatus]string{NotStarted: "[ ]", Started: "[>]", Done: "[x]"}[t.Status]
-			b.WriteString(fmt.Sprintf("%s %s %s - %s\n", cursor, statusStr, t.Code, t.Title))
 		}
-		b.WriteString("\n" + m.input.View())
-		b.WriteString("\n\n↑/↓ to move • [Enter] to select • \\d to delete • esc to go back • \\q to quit")
+		b.WriteString("\n" + m.inputView())
+		b.WriteString(m.statusLine())
+		b.WriteString(m.footer())
 	} else {
-		for i, it := range m.items {
+		if t, ok := m.openedTask(); ok && t.Description != "" {
+			b.WriteString(t.Description + "\n\n")
+		}
+		if m.live.goal != "" {
+			b.WriteString(m.live.goal + "\n\n")
+		}
+		if m.manualOrder {
+			b.WriteString("(manual order)\n")
+		}
+		if m.itemFilter != showAll {
+			b.WriteString(fmt.Sprintf("(%s)\n", m.itemFilter))
+		}
+		rows := m.items
+		switch {
+		case len(rows) == 0 && m.itemFilter != showAll:
+			b.WriteString(m.emptyState("No matches — \\f to change the filter"))
+		case len(rows) == 0:
+			b.WriteString(m.emptyState("No items yet — type to add one"))
+		case m.board:
+			m.viewBoard(&b)
+			rows = nil
+		}
+		nextUp, _ := nextInTask(rows)
+		for i, it := range rows {
 			cursor := " "
 			if i == m.cursor {
 				cursor = ">"
 			}
-			statusStr := map[itemStatus]string{NotStarted: "[ ]", Started: "[>]", Done: "[x]"}[it.Status]
+			if m.grab != nil && it.ID == m.grab.id {
+				cursor = "↕"
+			}
+			mark := " "
+			if m.marked[it.ID] {
+				mark = "*"
+			}
 			duration := it.FrozenDuration
-			if it.Status == Started && !m.paused {
-				duration = time.Since(it.CreatedAt)
+			if !m.paused {
+				duration = it.elapsed(time.Now())
+			}
+			when := formatDuration(duration)
+			if m.clockTimes {
+				when = clockTimes(it, time.Now())
+			} else if m.remaining && it.Estimate > 0 {
+				when = remainingLabel(it.Estimate - duration)
+			}
+			text := it.Text
+			if it.Priority {
+				text = "! " + text
+			}
+			text += refMarker(it.Ref)
+			if it.Note != "" {
+				text += " ✎"
+			}
+			if it.workedOn(time.Now()) {
+				text += " •today"
 			}
-			b.WriteString(fmt.Sprintf("%s %s %s (%s)\n", cursor, statusStr, it.Text, duration.Round(time.Second)))
+			if it.ID == nextUp.ID {
+				text += " → next"
+			}
+			clock := ""
+			if it.Estimate > 0 {
+				clock = " / est " + formatDuration(it.Estimate)
+			}
+			if it.Pomodoros > 0 {
+				clock += fmt.Sprintf(", pomodoros: %d", it.Pomodoros)
+			}
+			if it.Status == Started && it.ClockedOut {
+				clock += ", clocked out"
+			} else if it.Status == Started && duration > longRunningFor {
+				clock += ", still running?"
+			}
+			marker := m.itemMarker(it, time.Now())
+			if m.inlineEditing(i) {
+				b.WriteString(fmt.Sprintf("%s%s%s %s\n", cursor, mark, marker, m.inlineInput()))
+				continue
+			}
+			if m.hideTimers {
+				b.WriteString(fmt.Sprintf("%s%s%s %s%s%s\n", cursor, mark, marker, m.numberPrefix(i), m.idPrefix(it.ID), text))
+				continue
+			}
+			b.WriteString(fmt.Sprintf("%s%s%s %s%s%s (%s%s)\n", cursor, mark, marker, m.numberPrefix(i), m.idPrefix(it.ID), text, when, clock))
 		}
-		b.WriteString("\n" + m.input.View())
-		b.WriteString("\n\n↑/↓ to move • [Space] to toggle • esc to go back • \\d to delete • \\q to quit")
+		b.WriteString("\n" + m.inputView())
+		b.WriteString(m.statusLine())
+		b.WriteString(m.footer())
 	}
 	return b.String()
 }
 
+// remainingLabel counts an estimate down; once it runs out the overrun
+// is shown in red.
+func remainingLabel(left time.Duration) string {
+	if left < 0 {
+		return paint("over by "+formatDuration(-left), red)
+	}
+	return formatDuration(left) + " left"
+}
+
+func (m model) numberPrefix(i int) string {
+	if !m.numbered {
+		return ""
+	}
+	return fmt.Sprintf("%2d. ", i+1)
+}
+
+func (m model) idPrefix(id int64) string {
+	if !m.showIDs {
+		return ""
+	}
+	return fmt.Sprintf("#%d ", id)
+}
+
+// emptyState stands in for a list with nothing to show, centered when the
+// terminal width is known.
+func (m model) emptyState(msg string) string {
+	pad := max((m.width-len([]rune(msg)))/2, 0)
+	return strings.Repeat(" ", pad) + msg + "\n"
+}
+
+func (m model) statusLine() string {
+	if m.confirm != nil {
+		return "\n" + m.confirm.view()
+	}
+	if m.prompt != nil && m.status == "" {
+		return "\n" + m.prompt.label + " (enter to confirm, esc to cancel)"
+	}
+	return "\n" + m.status
+}
+
 func main() {
-	if err := tea.NewProgram(initialModel(), tea.WithAltScreen()).Start(); err != nil {
+	cmdMode := flag.Bool("cmd", false, "read JSON commands from stdin instead of starting the TUI")
+	dbPath := flag.String("db", "./checklist.db", `database file, or ":memory:" for a throwaway session`)
+	noAltScreen := flag.Bool("no-altscreen", false, "draw inline so the final screen stays in the terminal")
+	mono := flag.Bool("mono", false, "never color the output")
+	flag.Parse()
+	if err := openSlowLog(); err != nil {
+		fmt.Fprintln(os.Stderr, "CHRONOLIST_DEBUG:", err)
+		os.Exit(1)
+	}
+	if !*mono {
+		colors = detectColorLevel(os.Getenv, isTerminal(os.Stdout))
+	}
+
+	if flag.Arg(0) == "list" {
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		if err := runList(db, os.Stdout, flag.Arg(1)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "share" {
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		t, err := resolveTask(db, command{Task: flag.Arg(1)})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, flag.Arg(1)+":", err)
+			os.Exit(1)
+		}
+		fmt.Println(taskSnippet(t, loadItems(db, t.ID), time.Now()))
+		return
+	}
+
+	if flag.Arg(0) == "codes" {
+		include, err := parseStatuses(flag.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		for _, line := range taskCodes(loadTasks(db), include) {
+			fmt.Println(line)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "daily-csv" {
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		data, err := exportDailyCSV(db)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+
+	if flag.Arg(0) == "report" {
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		loadConfig(newStore(db))
+		to := flag.Arg(2)
+		if to == "" {
+			to = "today"
+		}
+		r, err := buildReport(db, flag.Arg(1), to, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		writeReport(os.Stdout, r)
+		return
+	}
+
+	if flag.Arg(0) == "import" {
+		data, err := os.ReadFile(flag.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		tasks, items, err := importMarkdown(db, data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Import failed, nothing was imported:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Imported %d tasks, %d items\n", tasks, items)
+		return
+	}
+
+	if *cmdMode {
+		db := mustOpenDB(*dbPath)
+		defer db.Close()
+		if err := runCommands(db, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading commands:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var opts []tea.ProgramOption
+	if !*noAltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if err := tea.NewProgram(newModel(newStore(mustOpenDB(*dbPath))), opts...).Start(); err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseTaskInput(t *testing.T) {
+	tests := []struct {
+		in, code, title string
+	}{
+		{"DEPLOY: Release v2", "DEPLOY", "Release v2"},
+		{"  ops-1:   Rotate keys ", "ops-1", "Rotate keys"},
+		{"Release v2", "", "Release v2"},
+		{"Note: no space after colon:x", "Note", "no space after colon:x"},
+		{"ratio 1:2", "", "ratio 1:2"},
+		{"two words: title", "", "two words: title"},
+		{"CODE:", "", "CODE:"},
+	}
+	for _, tt := range tests {
+		code, title := parseTaskInput(tt.in)
+		if code != tt.code || title != tt.title {
+			t.Errorf("parseTaskInput(%q) = %q, %q, want %q, %q", tt.in, code, title, tt.code, tt.title)
+		}
+	}
+}
+
+func TestCreateTaskCustomCode(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := createTask(db, "DEPLOY: Release v2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createTask(db, "deploy: Again"); !errors.Is(err, ErrConflict) {
+		t.Errorf("duplicate code: err = %v, want ErrConflict", err)
+	}
+	if _, err := createTask(db, "   "); err == nil {
+		t.Error("empty title was accepted")
+	}
+}
+
+func TestNextTaskCodeSkipsDeletedAndArchived(t *testing.T) {
+	db := newTestDB(t)
+	var ids []int64
+	for _, title := range []string{"a", "b", "c"} {
+		id, err := createTask(db, title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	deleteTask(db, ids[0])
+	archiveTasks(db, ids[1:2])
+	createTask(db, "T05: taken")
+
+	var codes []string
+	for i := 0; i < 2; i++ {
+		id, err := createTask(db, "new")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var code string
+		db.QueryRow("SELECT code FROM tasks WHERE id = ?", id).Scan(&code)
+		codes = append(codes, code)
+	}
+	if codes[0] != "T06" || codes[1] != "T07" {
+		t.Errorf("new codes = %v, want [T06 T07]", codes)
+	}
+}
+
+func TestScanItemNullColumns(t *testing.T) {
+	db := newTestDB(t)
+	taskID, _ := createTask(db, "Nulls")
+	db.Exec("INSERT INTO items (task_id, text, status, created_at, checked_at, frozen_duration) VALUES (?, 'ok', 2, '2026-01-02T10:00:00Z', '2026-01-02T11:00:00Z', 60000000000)", taskID)
+	db.Exec("INSERT INTO items (task_id, text, status, created_at, checked_at, frozen_duration) VALUES (?, 'no status', NULL, '2026-01-02T10:00:00Z', NULL, NULL)", taskID)
+	db.Exec("INSERT INTO items (task_id, text, status, created_at, checked_at, frozen_duration) VALUES (?, NULL, 0, NULL, NULL, NULL)", taskID)
+	db.Exec("INSERT INTO items (task_id, text, status) VALUES (NULL, 'orphan', 1)")
+
+	items := loadItems(db, taskID)
+	if len(items) != 3 {
+		t.Fatalf("loaded %d items, want 3", len(items))
+	}
+	tests := []struct {
+		text    string
+		status  itemStatus
+		frozen  time.Duration
+		checked bool
+	}{
+		{"ok", Done, time.Minute, true},
+		{"no status", NotStarted, 0, false},
+		{"", NotStarted, 0, false},
+	}
+	for i, tt := range tests {
+		it := items[i]
+		if it.Text != tt.text || it.Status != tt.status || it.FrozenDuration != tt.frozen || (it.CheckedAt != nil) != tt.checked {
+			t.Errorf("item %d = %q status %d frozen %s checked %v, want %q %d %s %v",
+				i, it.Text, it.Status, it.FrozenDuration, it.CheckedAt != nil, tt.text, tt.status, tt.frozen, tt.checked)
+		}
+	}
+	orphans := queryItems(db, "WHERE task_id IS NULL")
+	if len(orphans) != 1 || orphans[0].TaskID != 0 || orphans[0].Status != Started {
+		t.Errorf("orphan = %+v, want one Started item with no task", orphans)
+	}
+}
+
+func TestMergeItemsKeepsFields(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		items     []item
+		estimate  time.Duration
+		note, ref string
+		pomodoros int
+	}{
+		{
+			name:     "sums estimates",
+			items:    []item{{Text: "a", Estimate: time.Hour}, {Text: "b", Estimate: 30 * time.Minute}},
+			estimate: 90 * time.Minute,
+		},
+		{
+			name:  "joins notes",
+			items: []item{{Text: "a", Note: "first"}, {Text: "b"}, {Text: "c", Note: "second"}},
+			note:  "first\nsecond",
+		},
+		{
+			name:  "keeps the first ref",
+			items: []item{{Text: "a"}, {Text: "b", Ref: "JIRA-1"}},
+			ref:   "JIRA-1",
+		},
+		{
+			name:  "moves other refs to the note",
+			items: []item{{Text: "a", Ref: "JIRA-1", Note: "n"}, {Text: "b", Ref: "JIRA-2"}, {Text: "c", Ref: "JIRA-1"}},
+			ref:   "JIRA-1",
+			note:  "n\nJIRA-2",
+		},
+		{
+			name:      "sums pomodoros",
+			items:     []item{{Text: "a", Pomodoros: 2}, {Text: "b", Pomodoros: 1}},
+			pomodoros: 3,
+		},
+	}
+	for _, tt := range tests {
+		got := mergeItems(tt.items, now)
+		if got.Estimate != tt.estimate || got.Note != tt.note || got.Ref != tt.ref || got.Pomodoros != tt.pomodoros {
+			t.Errorf("%s: got estimate %v note %q ref %q pomodoros %d, want %v %q %q %d",
+				tt.name, got.Estimate, got.Note, got.Ref, got.Pomodoros, tt.estimate, tt.note, tt.ref, tt.pomodoros)
+		}
+	}
+}
+
+func TestSaveItemPersistsMergedFields(t *testing.T) {
+	db := newTestDB(t)
+	id, err := createTask(db, "Merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := item{TaskID: id, Text: "a; b", CreatedAt: time.Now(), Estimate: time.Hour, Note: "n", Ref: "JIRA-1", Pomodoros: 2}
+	if saveItem(db, want) == 0 {
+		t.Fatal("saveItem failed")
+	}
+	items := loadItems(db, id)
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	got := items[0]
+	if got.Estimate != want.Estimate || got.Note != want.Note || got.Ref != want.Ref || got.Pomodoros != want.Pomodoros {
+		t.Errorf("saved item = %+v, want estimate, note, ref and pomodoros of %+v", got, want)
+	}
+}
+
+func TestClockInOutCyclesSum(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		sittings [][2]time.Duration // clock in, clock out, as offsets from start
+		want     time.Duration
+	}{
+		{"one sitting", [][2]time.Duration{{0, 20 * time.Minute}}, 20 * time.Minute},
+		{"two sittings", [][2]time.Duration{{0, 20 * time.Minute}, {time.Hour, 90 * time.Minute}}, 50 * time.Minute},
+		{"three sittings", [][2]time.Duration{{0, time.Minute}, {10 * time.Minute, 15 * time.Minute}, {2 * time.Hour, 3 * time.Hour}}, 66 * time.Minute},
+	}
+	for _, tt := range tests {
+		it := item{Status: NotStarted}
+		for _, s := range tt.sittings {
+			toggleClock(&it, start.Add(s[0]))
+			if it.Status != Started || it.ClockedOut {
+				t.Fatalf("%s: clock in left %+v", tt.name, it)
+			}
+			toggleClock(&it, start.Add(s[1]))
+			if it.Status != Started || !it.ClockedOut {
+				t.Fatalf("%s: clock out left %+v", tt.name, it)
+			}
+		}
+		if got := it.elapsed(start.Add(24 * time.Hour)); got != tt.want {
+			t.Errorf("%s: elapsed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClockedOutSurvivesRestart(t *testing.T) {
+	db := newTestDB(t)
+	id, _ := createTask(db, "Clock")
+	now := time.Now()
+	it := item{TaskID: id, Text: "x"}
+	toggleClock(&it, now.Add(-time.Hour))
+	toggleClock(&it, now.Add(-30*time.Minute))
+	it.ID = saveItem(db, it)
+	got := loadItems(db, id)[0]
+	if !got.ClockedOut || got.Status != Started {
+		t.Fatalf("reloaded item = %+v, want started and clocked out", got)
+	}
+	if d := got.elapsed(now); d != 30*time.Minute {
+		t.Errorf("reloaded elapsed = %v, want 30m", d)
+	}
+}
+
+func TestSortDoneLast(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []item
+		want string
+	}{
+		{"already ordered", []item{{Text: "a"}, {Text: "b", Status: Done}}, "a,b"},
+		{"done sinks", []item{{Text: "a", Status: Done}, {Text: "b"}, {Text: "c", Status: Started}}, "b,c,a"},
+		{"skipped sinks too", []item{{Text: "a", Status: Skipped}, {Text: "b"}}, "b,a"},
+		{"stable among closed", []item{{Text: "a", Status: Done}, {Text: "b", Status: Skipped}, {Text: "c", Status: Done}, {Text: "d"}}, "d,a,b,c"},
+		{"stable among open", []item{{Text: "a", Status: Started}, {Text: "b", Status: Done}, {Text: "c"}}, "a,c,b"},
+	}
+	for _, tt := range tests {
+		sortDoneLast(tt.in)
+		if got := itemTexts(tt.in); got != tt.want {
+			t.Errorf("%s: order = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDoneLastCursorFollowsOpenWork(t *testing.T) {
+	m := newTestModel(t)
+	m.cfg.DoneLast = true
+	id, _ := m.store.CreateTask("Sink")
+	for i, text := range []string{"a", "b", "c"} {
+		m.store.SaveItem(item{TaskID: id, Text: text, Position: i + 1})
+	}
+	m.openTask(id)
+	m = press(m, " ", " ")
+	if got := itemTexts(m.items); got != "b,c,a" {
+		t.Fatalf("order after completing a = %s, want b,c,a", got)
+	}
+	if it := m.currentItem(); it == nil || it.Status.closed() {
+		t.Errorf("cursor is on %+v, want an open item", it)
+	}
+}
+
+func TestCursorStaysInBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  int
+		cursor int
+		mutate func(m *model)
+		want   int
+	}{
+		{"delete the last row", 3, 2, func(m *model) { m.deleteSelected() }, 1},
+		{"delete the only row", 1, 0, func(m *model) { m.deleteSelected() }, 0},
+		{"filter hides rows", 3, 2, func(m *model) {
+			m.store.SaveItemStatus(item{ID: m.items[0].ID, TaskID: m.selectedTaskID, Status: Done})
+			m.itemFilter = onlyDone
+			m.reloadItems()
+		}, 0},
+		{"rows removed underneath", 3, 2, func(m *model) {
+			for _, it := range m.items[1:] {
+				m.store.DeleteItem(it.ID)
+			}
+			m.reloadItems()
+		}, 0},
+		{"cursor past the end", 2, 9, func(m *model) { m.clampCursor() }, 1},
+		{"negative cursor", 2, -3, func(m *model) { m.clampCursor() }, 0},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.cfg.ConfirmThreshold = 0
+		id, _ := m.store.CreateTask("Clamp")
+		for i := range tt.items {
+			m.store.SaveItem(item{TaskID: id, Text: "x", Position: i + 1})
+		}
+		m.openTask(id)
+		m.cursor = tt.cursor
+		tt.mutate(&m)
+		if m.cursor != tt.want {
+			t.Errorf("%s: cursor = %d, want %d", tt.name, m.cursor, tt.want)
+		}
+	}
+}
+
+func TestEmptyListsDoNotPanic(t *testing.T) {
+	keys := []any{
+		tea.KeyEnter, tea.KeySpace, tea.KeyUp, tea.KeyDown, tea.KeyTab,
+		tea.KeyCtrlD, tea.KeyCtrlE, tea.KeyCtrlG, tea.KeyCtrlK, tea.KeyCtrlL,
+		tea.KeyCtrlO, tea.KeyCtrlP, tea.KeyCtrlR, tea.KeyCtrlS, tea.KeyCtrlT,
+		tea.KeyCtrlX, tea.KeyCtrlY, tea.KeyF2, tea.KeyF5,
+	}
+	commands := []string{"\\d", "\\r", "\\f", "\\skip", "\\split a; b", "\\merge", "\\est 1h", "\\spent 1h", "\\note n", "\\ref x", "\\reset", "\\board", "\\share"}
+	tests := []struct {
+		name      string
+		emptyTask bool
+	}{
+		{"empty task list", false},
+		{"empty task", true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		if tt.emptyTask {
+			id, _ := m.store.CreateTask("Empty")
+			m.reloadTasks()
+			m.openTask(id)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: panic: %v", tt.name, r)
+				}
+			}()
+			for _, k := range keys {
+				m = press(m, k, tea.KeyEsc)
+				if tt.emptyTask && m.selectedTaskID == 0 {
+					m.openTask(m.tasks[0].ID)
+				}
+			}
+			for _, c := range commands {
+				m = press(m, c, tea.KeyEnter, tea.KeyEsc)
+				if tt.emptyTask && m.selectedTaskID == 0 && len(m.tasks) > 0 {
+					m.openTask(m.tasks[0].ID)
+				}
+			}
+		}()
+	}
+}
+
+func TestMemoryDBTouchesNoFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	db := mustOpenDB(memoryDB)
+	defer db.Close()
+	m := newModel(newStore(db))
+	m = press(m, "+Groceries", tea.KeyEnter, tea.KeyEnter, "milk", tea.KeyEnter)
+	if got := len(m.store.Tasks()); got != 1 {
+		t.Fatalf("%d tasks, want 1", got)
+	}
+	if got := len(m.items); got != 1 {
+		t.Errorf("%d items, want 1", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("in-memory session wrote %s", e.Name())
+	}
+}
+
+func TestSetupDBOnMemoryMatchesFile(t *testing.T) {
+	schema := func(db *sql.DB) string {
+		rows, err := queryDB(db, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			rows.Scan(&name)
+			names = append(names, name)
+		}
+		return strings.Join(names, ",")
+	}
+	file := mustOpenDB(filepath.Join(t.TempDir(), "file.db"))
+	defer file.Close()
+	if got, want := schema(newTestDB(t)), schema(file); got != want {
+		t.Errorf("in-memory tables = %s, want %s", got, want)
+	}
+}
+
+func TestFormatDurationDays(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{90 * time.Second, "1m30s"},
+		{23*time.Hour + 59*time.Minute, "23h59m0s"},
+		{oneDay, "1d"},
+		{oneDay + 59*time.Minute, "1d"},
+		{52*time.Hour + 13*time.Minute + 9*time.Second, "2d 4h"},
+		{maxShownDays * oneDay, "99d"},
+		{(maxShownDays + 1) * oneDay, "99d+"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestLongRunningItemsAreFlagged(t *testing.T) {
+	tests := []struct {
+		name    string
+		running time.Duration
+		flagged bool
+	}{
+		{"an hour", time.Hour, false},
+		{"over a day", oneDay + time.Hour, true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		id, _ := m.store.CreateTask("Long")
+		m.store.SaveItem(item{TaskID: id, Text: "forgotten", Status: Started, StartedAt: time.Now().Add(-tt.running)})
+		m.openTask(id)
+		if got := strings.Contains(m.View(), "still running?"); got != tt.flagged {
+			t.Errorf("%s: flagged = %v, want %v", tt.name, got, tt.flagged)
+		}
+	}
+}
+
+func TestRollupWithSkippedItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []itemStatus
+		want     itemStatus
+	}{
+		{"all skipped but one done", []itemStatus{Done, Skipped, Skipped}, Done},
+		{"done and skipped", []itemStatus{Done, Done, Skipped}, Done},
+		{"one left open", []itemStatus{Done, Skipped, NotStarted}, Started},
+		{"only skipped", []itemStatus{Skipped, Skipped}, NotStarted},
+		{"skipped and not started", []itemStatus{Skipped, NotStarted}, NotStarted},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		id, _ := createTask(db, "Rollup")
+		for _, s := range tt.statuses {
+			saveItem(db, item{TaskID: id, Text: "x", Status: s, FrozenDuration: time.Hour})
+		}
+		updateTaskStatus(db, id)
+		if got := loadTasks(db)[0].Status; got != tt.want {
+			t.Errorf("%s: task status = %v, want %v", tt.name, got, tt.want)
+		}
+		done, total := countDone(loadItems(db, id))
+		wantTotal := 0
+		for _, s := range tt.statuses {
+			if s != Skipped {
+				wantTotal++
+			}
+		}
+		if total != wantTotal {
+			t.Errorf("%s: countDone total = %d (done %d), want %d", tt.name, total, done, wantTotal)
+		}
+	}
+}
+
+func TestSkippedTimeLeftOutOfTotals(t *testing.T) {
+	now := time.Now()
+	items := []item{
+		{Status: Skipped, FrozenDuration: time.Hour},
+		{Status: Started, ClockedOut: true, FrozenDuration: 10 * time.Minute},
+	}
+	if got := totalDuration(items, now); got != 10*time.Minute {
+		t.Errorf("totalDuration = %v, want 10m", got)
+	}
+	it := item{Status: Started, StartedAt: now.Add(-time.Hour)}
+	toggleSkip(&it, now)
+	if it.Status != Skipped || it.elapsed(now) != time.Hour {
+		t.Errorf("skipping a running item left %+v", it)
+	}
+	if statusMarker(Skipped) != "[-]" {
+		t.Errorf("skipped marker = %q, want [-]", statusMarker(Skipped))
+	}
+}
+
+func TestLoadAllItemsWithTask(t *testing.T) {
+	db := newTestDB(t)
+	a, _ := createTask(db, "OPS: Operations")
+	b, _ := createTask(db, "DEV: Development")
+	gone, _ := createTask(db, "OLD: Deleted")
+	saveItem(db, item{TaskID: a, Text: "deploy", Position: 1})
+	saveItem(db, item{TaskID: b, Text: "deploy", Position: 1})
+	dropped := saveItem(db, item{TaskID: b, Text: "dropped", Position: 2})
+	saveItem(db, item{TaskID: gone, Text: "lost"})
+	deleteItem(db, dropped)
+	deleteTask(db, gone)
+
+	items, err := loadAllItemsWithTask(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ code, title, text, label string }{
+		{"OPS", "Operations", "deploy", "OPS: deploy"},
+		{"DEV", "Development", "deploy", "DEV: deploy"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i, w := range want {
+		it := items[i]
+		if it.TaskCode != w.code || it.TaskTitle != w.title || it.Text != w.text || it.String() != w.label {
+			t.Errorf("item %d = %s (%s, %q), want %s (%s, %q)", i, it, it.TaskTitle, it.Text, w.label, w.title, w.text)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	headingRe  = regexp.MustCompile(`^#+\s+(.*)$`)
+	checkboxRe = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s+(.*)$`)
+)
+
+type mdItem struct {
+	text string
+	done bool
+}
+
+type mdSection struct {
+	title string
+	items []mdItem
+}
+
+// parseMarkdown splits data into a section per heading holding its
+// checkboxes. Checkboxes before the first heading land in a leading
+// section with no title; all other lines are skipped.
+func parseMarkdown(data []byte) ([]mdSection, error) {
+	sections := []mdSection{{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			sections = append(sections, mdSection{title: strings.TrimSpace(m[1])})
+			continue
+		}
+		if m := checkboxRe.FindStringSubmatch(line); m != nil && strings.TrimSpace(m[2]) != "" {
+			last := &sections[len(sections)-1]
+			last.items = append(last.items, mdItem{text: strings.TrimSpace(m[2]), done: m[1] != " "})
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// importMarkdown creates a task for every heading and an item for every
+// checkbox under it. Checked boxes become Done items with no time on them;
+// checkboxes before the first heading are skipped. The import is all or
+// nothing: on any error the database is left as it was.
+func importMarkdown(db *sql.DB, data []byte) (tasks, items int, err error) {
+	sections, err := parseMarkdown(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	now := time.Now()
+	for _, s := range sections[1:] {
+		taskID, err := createTask(tx, s.title)
+		if err != nil {
+			tx.Rollback()
+			return 0, 0, fmt.Errorf("%s: %w", s.title, err)
+		}
+		for _, mi := range s.items {
+			it := item{TaskID: taskID, Text: mi.text, Status: NotStarted, CreatedAt: now}
+			if mi.done {
+				it.Status = Done
+				it.CheckedAt = &now
+			}
+			if saveItem(tx, it) == 0 {
+				tx.Rollback()
+				return 0, 0, fmt.Errorf("could not save %q", mi.text)
+			}
+			items++
+		}
+		updateTaskStatus(tx, taskID)
+		tasks++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return tasks, items, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // "title:text=done,..." per section, sections joined with "|"
+	}{
+		{"headings and boxes", "# Trip\n- [ ] pack\n- [x] book\n## Work\n* [X] ship\n", ":|Trip:pack=false,book=true|Work:ship=true"},
+		{"prose and bullets are skipped", "# Notes\nSome prose.\n- plain bullet\n- [ ] real\n1. [ ] numbered\n", ":|Notes:real=false"},
+		{"boxes before a heading", "- [ ] loose\n# Later\n", ":loose=false|Later:"},
+		{"empty box text is skipped", "# T\n- [ ]   \n+ [ ] ok\n", ":|T:ok=false"},
+		{"indented lines", "  # Indented\n    - [x] nested\n", ":|Indented:nested=true"},
+	}
+	for _, tt := range tests {
+		sections, err := parseMarkdown([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var parts []string
+		for _, s := range sections {
+			var items []string
+			for _, it := range s.items {
+				items = append(items, it.text+"="+map[bool]string{true: "true", false: "false"}[it.done])
+			}
+			parts = append(parts, s.title+":"+strings.Join(items, ","))
+		}
+		if got := strings.Join(parts, "|"); got != tt.want {
+			t.Errorf("%s: parseMarkdown = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	db := newTestDB(t)
+	tasks, items, err := importMarkdown(db, []byte("- [ ] loose\n# Trip\n- [ ] pack\n- [x] book\n# Empty\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks != 2 || items != 2 {
+		t.Fatalf("imported %d tasks and %d items, want 2 and 2", tasks, items)
+	}
+	loaded := loadTasks(db)
+	var trip task
+	for _, tk := range loaded {
+		if tk.Title == "Trip" {
+			trip = tk
+		}
+	}
+	got := loadItems(db, trip.ID)
+	if len(got) != 2 || got[0].Status != NotStarted || got[1].Status != Done || got[1].CheckedAt == nil || got[1].FrozenDuration != 0 {
+		t.Errorf("Trip items = %+v, want pack open and book done with no time", got)
+	}
+	if trip.Status != Started {
+		t.Errorf("Trip status = %v, want started", trip.Status)
+	}
+}
+
+func TestImportsAreAllOrNothing(t *testing.T) {
+	doc := "# First\n- [ ] one\n- [ ] two\n# Second\n- [ ] three\n- [ ] boom\n- [ ] four\n"
+	tests := []struct {
+		name string
+		run  func(db *sql.DB) error
+	}{
+		{"markdown import", func(db *sql.DB) error {
+			_, _, err := importMarkdown(db, []byte(doc))
+			return err
+		}},
+		{"checklist file", func(db *sql.DB) error {
+			path := filepath.Join(t.TempDir(), "list.md")
+			if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadChecklistFile(db, path, "")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		// Fail the insert partway through, after earlier rows have gone in.
+		if _, err := db.Exec(`CREATE TRIGGER fail_boom BEFORE INSERT ON items
+			WHEN NEW.text = 'boom' BEGIN SELECT RAISE(ABORT, 'injected failure'); END`); err != nil {
+			t.Fatal(err)
+		}
+		beforeTasks := len(loadTasks(db))
+		if err := tt.run(db); err == nil {
+			t.Errorf("%s: no error from the failed import", tt.name)
+		}
+		var items int
+		db.QueryRow("SELECT COUNT(*) FROM items").Scan(&items)
+		if got := len(loadTasks(db)); got != beforeTasks || items != 0 {
+			t.Errorf("%s: %d tasks and %d items after the failure, want %d and 0", tt.name, got, items, beforeTasks)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+var migrations = []func(tx *sql.Tx) error{
+	migrateItemsForeignKey,
+	migrateItemsDurationSeconds,
+	migrateItemsClockedOut,
+	migrateItemsPosition,
+	migrateItemsEstimate,
+	migrateTasksDue,
+	migrateSoftDelete,
+	migrateItemsPriority,
+	migrateTasksLastActivity,
+	migrateItemsStartedAt,
+	migrateRefs,
+	migrateTasksDescription,
+	migrateItemsPomodoros,
+	migrateStampsToUTC,
+	migrateItemsNote,
+	migrateTasksDailyGoal,
+	migrateTasksArchived,
+}
+
+func migrate(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var version int
+	if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version >= len(migrations) {
+		return nil
+	}
+
+	// Table rebuilds require foreign keys to be off, and the pragma is a no-op inside a transaction.
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := migrations[i](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateItemsForeignKey(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE items_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE,
+			text TEXT,
+			status INTEGER,
+			created_at TEXT,
+			checked_at TEXT,
+			frozen_duration INTEGER
+		)`,
+		`INSERT INTO items_new (id, task_id, text, status, created_at, checked_at, frozen_duration)
+			SELECT id, task_id, text, status, created_at, checked_at, frozen_duration FROM items`,
+		`DROP TABLE items`,
+		`ALTER TABLE items_new RENAME TO items`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateItemsDurationSeconds(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE items ADD COLUMN duration_seconds INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE items SET duration_seconds = COALESCE(frozen_duration, 0) / ?", int64(time.Second))
+	return err
+}
+
+func migrateItemsClockedOut(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN clocked_out INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func migrateItemsPosition(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE items ADD COLUMN position INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE items SET position = id")
+	return err
+}
+
+func migrateItemsEstimate(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func migrateTasksDue(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE tasks ADD COLUMN due_at TEXT NOT NULL DEFAULT ''")
+	return err
+}
+
+func migrateSoftDelete(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE tasks ADD COLUMN deleted_at TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN deleted_at TEXT NOT NULL DEFAULT ''")
+	return err
+}
+
+func migrateItemsPriority(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN priority INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func migrateTasksLastActivity(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE tasks ADD COLUMN last_activity_at TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`UPDATE tasks SET last_activity_at = COALESCE(
+		(SELECT MAX(MAX(created_at, checked_at)) FROM items WHERE items.task_id = tasks.id), '')`)
+	return err
+}
+
+func migrateItemsStartedAt(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE items ADD COLUMN started_at TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE items SET started_at = COALESCE(created_at, '')")
+	return err
+}
+
+func migrateRefs(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE tasks ADD COLUMN ref TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN ref TEXT NOT NULL DEFAULT ''")
+	return err
+}
+
+func migrateTasksDescription(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE tasks ADD COLUMN description TEXT NOT NULL DEFAULT ''")
+	return err
+}
+
+func migrateItemsPomodoros(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN pomodoros INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+// migrateStampsToUTC rewrites timestamps stored with a local offset as UTC.
+// Each row kept its offset, so the conversion is exact.
+func migrateStampsToUTC(tx *sql.Tx) error {
+	columns := map[string][]string{
+		"tasks": {"due_at", "deleted_at", "last_activity_at"},
+		"items": {"created_at", "checked_at", "started_at", "deleted_at"},
+	}
+	for table, cols := range columns {
+		for _, col := range cols {
+			stmt := fmt.Sprintf(`UPDATE %[1]s SET %[2]s = strftime('%%Y-%%m-%%dT%%H:%%M:%%SZ', %[2]s)
+				WHERE %[2]s LIKE '____-__-__T%%' AND %[2]s NOT LIKE '%%Z' AND strftime('%%s', %[2]s) IS NOT NULL`, table, col)
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func migrateItemsNote(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items ADD COLUMN note TEXT NOT NULL DEFAULT ''")
+	return err
+}
+
+func migrateTasksDailyGoal(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE tasks ADD COLUMN daily_goal INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func migrateTasksArchived(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE tasks ADD COLUMN archived_at TEXT NOT NULL DEFAULT ''")
+	return err
+}
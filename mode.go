@@ -0,0 +1,77 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// In modal mode (the "modal" setting) the input only takes keys while
+// inserting. Navigation mode leaves plain letters free for shortcuts.
+
+func (m *model) setNavMode(on bool) {
+	m.navMode = on
+	m.syncFocus()
+}
+
+func (m *model) syncFocus() {
+	if m.navMode && m.prompt == nil && !m.batchAdd {
+		m.input.Blur()
+	} else {
+		m.input.Focus()
+	}
+}
+
+func (m model) inputView() string {
+	if m.prompt != nil && m.prompt.inline {
+		return ""
+	}
+	if m.navMode {
+		return "-- NAV -- i to type • j/k to move • e to edit • x to complete • d to delete"
+	}
+	if m.cfg.Modal {
+		return m.input.View() + "  -- INSERT --"
+	}
+	return m.input.View()
+}
+
+// updateNav handles the single-letter shortcuts of navigation mode and
+// reports whether it consumed the key.
+func (m model) updateNav(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "i":
+		m.setNavMode(false)
+	case "j":
+		m.moveCursor(1)
+	case "k":
+		m.moveCursor(-1)
+	case "g":
+		m.cursor = 0
+	case "G":
+		m.cursor = m.rowCount() - 1
+		m.clampCursor()
+	case "d":
+		m.deleteSelected()
+	case "e":
+		m.editSelected()
+	case "v":
+		m.openDetail()
+	case "x":
+		if m.selectedTaskID != 0 {
+			m.completeAndAdvance()
+		}
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+func (m model) rowCount() int {
+	if m.selectedTaskID != 0 {
+		return len(m.items)
+	}
+	return len(m.tasks)
+}
+
+func (m *model) moveCursor(delta int) {
+	m.cursor += delta
+	m.clampCursor()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	orphanWhere  = "WHERE task_id IS NULL OR task_id NOT IN (SELECT id FROM tasks)"
+	recoveryCode = "RECOVERED"
+)
+
+func findOrphans(db querier) ([]item, error) {
+	rows, err := queryDB(db, "SELECT "+itemColumns+" FROM items "+orphanWhere)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := scanItems(rows)
+	return items, rows.Err()
+}
+
+func deleteOrphans(db querier) (int64, error) {
+	res, err := execDB(db, "DELETE FROM items "+orphanWhere)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func recoverOrphans(db querier) (int64, error) {
+	taskID, err := reservedTask(db, recoveryCode, "Recovered items")
+	if err != nil {
+		return 0, fmt.Errorf("could not create the %s task: %w", recoveryCode, err)
+	}
+	res, err := execDB(db, "UPDATE items SET task_id = ? "+orphanWhere, taskID)
+	if err != nil {
+		return 0, err
+	}
+	updateTaskStatus(db, taskID)
+	return res.RowsAffected()
+}
+
+func (m *model) repairOrphans(arg string) {
+	orphans, err := m.store.Orphans()
+	if err != nil {
+		m.setStatus("Orphan check failed: " + err.Error())
+		return
+	}
+	if len(orphans) == 0 {
+		m.setStatus("No orphaned items")
+		return
+	}
+
+	var repair func(Storage) (int64, error)
+	var verb string
+	switch arg {
+	case "":
+		m.setStatus(fmt.Sprintf("%d orphaned items: \\orphans delete or \\orphans recover (moves them to %s)", len(orphans), recoveryCode))
+		return
+	case "delete":
+		repair, verb = Storage.DeleteOrphans, "Deleted"
+	case "recover":
+		repair, verb = Storage.RecoverOrphans, "Recovered"
+	default:
+		m.setStatus("Usage: \\orphans [delete|recover]")
+		return
+	}
+	m.confirm = &confirmation{
+		prompt:  fmt.Sprintf("%s %d orphaned items? y/n", verb, len(orphans)),
+		preview: itemPreview(orphans, time.Now()),
+		action: func(m *model) {
+			n, err := repair(m.store)
+			if err != nil {
+				m.setStatus("Orphan repair failed: " + err.Error())
+				return
+			}
+			if m.selectedTaskID == 0 {
+				m.reloadTasks()
+			}
+			m.setStatus(fmt.Sprintf("%s %d orphaned items", verb, n))
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestRecoverOrphansReusesRecoveredTask(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup string // run against an earlier RECOVERED task, "" for none
+	}{
+		{"no recovered task yet", ""},
+		{"live recovered task", "UPDATE tasks SET title = title WHERE id = ?"},
+		{"recovered task in the trash", "UPDATE tasks SET deleted_at = '2026-01-01T00:00:00Z' WHERE id = ?"},
+		{"archived recovered task", "UPDATE tasks SET archived_at = '2026-01-01T00:00:00Z' WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		var old int64
+		if tt.setup != "" {
+			var err error
+			if old, err = createTask(db, recoveryCode+": Recovered items"); err != nil {
+				t.Fatal(err)
+			}
+			execDB(db, tt.setup, old)
+		}
+		db.Exec("INSERT INTO items (task_id, text, status) VALUES (NULL, 'lost', 0), (NULL, 'stray', 0)")
+		n, err := recoverOrphans(db)
+		if err != nil || n != 2 {
+			t.Fatalf("%s: recoverOrphans = %d, %v, want 2", tt.name, n, err)
+		}
+		var ids []int64
+		rows, _ := db.Query("SELECT id FROM tasks WHERE code = ?", recoveryCode)
+		for rows.Next() {
+			var id int64
+			rows.Scan(&id)
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if len(ids) != 1 || (old != 0 && ids[0] != old) {
+			t.Errorf("%s: %s tasks = %v, want just the earlier one %d", tt.name, recoveryCode, ids, old)
+			continue
+		}
+		if !taskExists(db, ids[0]) || len(loadItems(db, ids[0])) != 2 {
+			t.Errorf("%s: recovered items are not in a live %s task", tt.name, recoveryCode)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A global pause clocks out every running item and remembers which ones it
+// stopped, so resuming clocks them back in with the paused interval left
+// out. Both are kept in settings so a pause survives a restart.
+
+func loadPause(db querier) (at time.Time, ids []int64, ok bool) {
+	at, err := parseStamp(getSetting(db, "paused_at"))
+	if err != nil {
+		return time.Time{}, nil, false
+	}
+	for _, s := range strings.Split(getSetting(db, "paused_items"), ",") {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return at, ids, true
+}
+
+func savePause(db querier, at time.Time, ids []int64) {
+	parts := []string{}
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	setSetting(db, "paused_at", formatStamp(at))
+	setSetting(db, "paused_items", strings.Join(parts, ","))
+}
+
+func clearPause(db querier) {
+	setSetting(db, "paused_at", "")
+	setSetting(db, "paused_items", "")
+}
+
+// holdTimers clocks out whatever is running, including items started since
+// the pause began.
+func (m *model) holdTimers(now time.Time) {
+	running := m.store.RunningItems()
+	if len(running) == 0 {
+		return
+	}
+	_, ids, _ := m.store.Pause()
+	for _, it := range running {
+		toggleClock(&it, now)
+		m.store.SaveItemStatus(it)
+		m.store.UpdateTaskStatus(it.TaskID)
+		ids = append(ids, it.ID)
+	}
+	m.store.SavePause(m.pausedAt, ids)
+	m.reloadItems()
+}
+
+func (m *model) togglePause(now time.Time) {
+	if !m.paused {
+		m.paused, m.pausedAt = true, now
+		m.store.SavePause(now, nil)
+		m.holdTimers(now)
+		m.setStatus("Paused all timers")
+		return
+	}
+	_, ids, _ := m.store.Pause()
+	for _, id := range ids {
+		if it, ok := m.store.Item(id); ok && it.Status == Started && it.ClockedOut {
+			toggleClock(&it, now)
+			m.store.SaveItemStatus(it)
+		}
+	}
+	m.store.ClearPause()
+	m.paused = false
+	m.reloadItems()
+	m.setStatus("Resumed after " + formatDuration(now.Sub(m.pausedAt)))
+}
+
+func (m model) pauseBanner(now time.Time) string {
+	if !m.paused {
+		return ""
+	}
+	return "=== PAUSED for " + formatDuration(now.Sub(m.pausedAt)) + ", \\pause to resume ===\n\n"
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPauseLeavesOutThePausedInterval(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name        string
+		ranFor      time.Duration // before the pause
+		pausedFor   time.Duration
+		after       time.Duration // after resuming
+		wantElapsed time.Duration
+	}{
+		{"short pause", 10 * time.Minute, 5 * time.Minute, 5 * time.Minute, 15 * time.Minute},
+		{"long pause", time.Minute, 40 * time.Minute, 0, time.Minute},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		id, _ := createTask(db, "Pause")
+		saveItem(db, item{TaskID: id, Text: "x", Status: Started, StartedAt: start})
+		m := newModel(newStore(db))
+		m.openTask(id)
+		pausedAt := start.Add(tt.ranFor)
+		m.togglePause(pausedAt)
+		if !strings.Contains(m.View(), "PAUSED") {
+			t.Errorf("%s: no PAUSED banner", tt.name)
+		}
+		// The pause survives a restart.
+		m = newModel(newStore(db))
+		if !m.paused || !m.pausedAt.Equal(pausedAt) {
+			t.Errorf("%s: restarted with paused %v at %v", tt.name, m.paused, m.pausedAt)
+		}
+		resumed := pausedAt.Add(tt.pausedFor)
+		m.togglePause(resumed)
+		if strings.Contains(m.View(), "PAUSED") {
+			t.Errorf("%s: PAUSED banner after resuming", tt.name)
+		}
+		it := m.store.Items(id)[0]
+		if got := it.elapsed(resumed.Add(tt.after)); got != tt.wantElapsed {
+			t.Errorf("%s: elapsed = %v, want %v", tt.name, got, tt.wantElapsed)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type pomodoro struct {
+	itemID  int64
+	text    string
+	onBreak bool
+	endsAt  time.Time
+}
+
+func addPomodoro(db querier, itemID int64) {
+	execDB(db, "UPDATE items SET pomodoros = pomodoros + 1 WHERE id = ?", itemID)
+}
+
+func (m *model) startPomodoro(arg string) {
+	if arg == "stop" {
+		if m.pomodoro == nil {
+			m.setStatus("No pomodoro running")
+			return
+		}
+		m.pomodoro = nil
+		m.setStatus("Pomodoro stopped")
+		return
+	}
+	i := m.currentItem()
+	if i == nil || m.taskGone() {
+		m.setStatus("Select an item to focus on")
+		return
+	}
+	if i.Status != Started || i.ClockedOut {
+		if i.Status == Started {
+			toggleClock(i, time.Now())
+		} else {
+			reopenItem(i, time.Now())
+		}
+		m.store.SaveItemStatus(*i)
+		m.store.UpdateTaskStatus(m.selectedTaskID)
+	}
+	m.pomodoro = &pomodoro{itemID: i.ID, text: i.Text, endsAt: time.Now().Add(m.cfg.PomodoroWork)}
+	m.setStatus("Focus on " + i.Text + " for " + formatDuration(m.cfg.PomodoroWork))
+}
+
+// tickPomodoro advances the work/break cycle and rings when a phase ends.
+func (m *model) tickPomodoro(now time.Time) tea.Cmd {
+	p := m.pomodoro
+	if p == nil || now.Before(p.endsAt) {
+		return nil
+	}
+	if p.onBreak {
+		m.pomodoro = nil
+		m.setStatus("Break over, \\pomo to start another")
+		return bell
+	}
+	m.store.AddPomodoro(p.itemID)
+	if m.selectedTaskID != 0 {
+		m.reloadItems()
+	}
+	p.onBreak = true
+	p.endsAt = now.Add(m.cfg.PomodoroBreak)
+	m.setStatus("Pomodoro done, take a " + formatDuration(m.cfg.PomodoroBreak) + " break")
+	return bell
+}
+
+func (m model) pomodoroLine(now time.Time) string {
+	p := m.pomodoro
+	if p == nil {
+		return ""
+	}
+	left := max(p.endsAt.Sub(now), 0).Round(time.Second)
+	countdown := fmt.Sprintf("%02d:%02d", int(left.Minutes()), int(left.Seconds())%60)
+	if p.onBreak {
+		return "Break " + countdown + " left\n\n"
+	}
+	return "Focus " + countdown + " left on " + p.text + "\n\n"
+}
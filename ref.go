@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+)
+
+func refMarker(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	return " ↗"
+}
+
+func parseRef(ref string) (*url.URL, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Scheme != "file") {
+		return nil, fmt.Errorf("%q is not a URL", ref)
+	}
+	return u, nil
+}
+
+func openURL(u *url.URL) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u.String())
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u.String())
+	default:
+		cmd = exec.Command("xdg-open", u.String())
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func (m *model) setRef(ref string) {
+	if t, ok := m.currentTask(); ok {
+		m.store.SetTaskRef(t.ID, ref)
+		m.reloadTasks()
+	} else if it := m.currentItem(); it != nil && !m.taskGone() {
+		it.Ref = ref
+		m.store.SetItemRef(it.ID, ref)
+	} else {
+		return
+	}
+	if ref == "" {
+		m.setStatus("Link cleared")
+	} else {
+		m.setStatus("Linked " + ref)
+	}
+}
+
+func (m *model) openRef() {
+	ref := ""
+	if t, ok := m.currentTask(); ok {
+		ref = t.Ref
+	} else if m.selectedTaskID != 0 && !m.taskGone() {
+		if it := m.currentItem(); it != nil {
+			ref = it.Ref
+		}
+		if ref == "" {
+			t, _ := m.store.ResolveTask(command{TaskID: m.selectedTaskID})
+			ref = t.Ref
+		}
+	}
+	if ref == "" {
+		m.setStatus("No link to open")
+		return
+	}
+	u, err := parseRef(ref)
+	if err != nil {
+		m.setStatus(err.Error())
+		return
+	}
+	if err := openURL(u); err != nil {
+		m.setStatus("Could not open link: " + err.Error())
+		return
+	}
+	m.setStatus("Opened " + u.String())
+}
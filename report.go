@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type taskTotal struct {
+	Code  string
+	Title string
+	Items int
+	Spent time.Duration
+}
+
+type report struct {
+	from, to time.Time
+	totals   []taskTotal
+}
+
+// reportRange turns two dates into [from, until), where until is the start
+// of the day after to so both named days count in full.
+func reportRange(fromArg, toArg string, now time.Time) (from, until time.Time, err error) {
+	if from, err = parseDue(fromArg, now); err != nil {
+		return from, until, err
+	}
+	to, err := parseDue(toArg, now)
+	if err != nil {
+		return from, until, err
+	}
+	from, to = startOfDay(from), startOfDay(to)
+	if to.Before(from) {
+		return from, until, fmt.Errorf("start %s is after end %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+func loadCompletedBetween(db querier, from, until time.Time) ([]itemWithTask, error) {
+	return queryItemsWithTask(db, "AND i.status = ? AND i.checked_at >= ? AND i.checked_at < ?", Done, formatStamp(from), formatStamp(until))
+}
+
+// totalsByTask sums time spent per task, keeping the order items came in.
+// With count_done_time off the time of Done items is left out, as it is
+// from the task total, and only the items are counted.
+func totalsByTask(items []itemWithTask) []taskTotal {
+	totals := []taskTotal{}
+	at := map[int64]int{}
+	for _, it := range items {
+		n, ok := at[it.TaskID]
+		if !ok {
+			n = len(totals)
+			at[it.TaskID] = n
+			totals = append(totals, taskTotal{Code: it.TaskCode, Title: it.TaskTitle})
+		}
+		totals[n].Items++
+		if countDoneTime || it.Status != Done {
+			totals[n].Spent += it.FrozenDuration
+		}
+	}
+	return totals
+}
+
+func buildReport(db querier, fromArg, toArg string, now time.Time) (report, error) {
+	from, until, err := reportRange(fromArg, toArg, now)
+	if err != nil {
+		return report{}, err
+	}
+	items, err := loadCompletedBetween(db, from, until)
+	if err != nil {
+		return report{}, err
+	}
+	return report{from: from, to: until.AddDate(0, 0, -1), totals: totalsByTask(items)}, nil
+}
+
+func (r report) header() string {
+	return fmt.Sprintf("Completed %s to %s, both days included\n\n", r.from.Format("2006-01-02"), r.to.Format("2006-01-02"))
+}
+
+func writeReport(w io.Writer, r report) {
+	var items int
+	var spent time.Duration
+	io.WriteString(w, r.header())
+	for _, t := range r.totals {
+		fmt.Fprintf(w, "%-6s %-30s %3d items %10s\n", t.Code, t.Title, t.Items, formatDuration(t.Spent))
+		items += t.Items
+		spent += t.Spent
+	}
+	if len(r.totals) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%-37s %3d items %10s\n", "Total", items, formatDuration(spent))
+}
+
+func (m *model) openReport(arg string) {
+	fromArg, toArg, _ := strings.Cut(arg, " ")
+	if fromArg == "" {
+		m.setStatus("Usage: \\report <from> [to], dates as 2006-01-02")
+		return
+	}
+	if toArg = strings.TrimSpace(toArg); toArg == "" {
+		toArg = "today"
+	}
+	r, err := m.store.Report(fromArg, toArg, time.Now())
+	if err != nil {
+		m.setStatus("Report failed: " + err.Error())
+		return
+	}
+	m.report = &r
+}
+
+func (m model) updateReport(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, m.quit()
+	}
+	m.report = nil
+	return m, nil
+}
+
+func (m model) viewReport(b *strings.Builder) {
+	if len(m.report.totals) == 0 {
+		b.WriteString(m.report.header())
+		b.WriteString(m.emptyState("Nothing was completed in this range"))
+		b.WriteString("\nany key to go back")
+		return
+	}
+	writeReport(b, *m.report)
+	b.WriteString("\nany key to go back")
+}
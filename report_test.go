@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTotalDurationCountDoneTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	done := now.Add(-time.Hour)
+	items := []item{
+		{Status: Done, FrozenDuration: time.Hour, CheckedAt: &done},
+		{Status: Started, StartedAt: now.Add(-10 * time.Minute)},
+		{Status: Skipped, FrozenDuration: 5 * time.Minute},
+		{Status: NotStarted},
+	}
+	tests := []struct {
+		countDone bool
+		want      time.Duration
+	}{
+		{true, 70 * time.Minute},
+		{false, 10 * time.Minute},
+	}
+	defer func(v bool) { countDoneTime = v }(countDoneTime)
+	for _, tt := range tests {
+		countDoneTime = tt.countDone
+		if got := totalDuration(items, now); got != tt.want {
+			t.Errorf("count_done_time=%v: totalDuration = %v, want %v", tt.countDone, got, tt.want)
+		}
+	}
+}
+
+func TestReportCountDoneTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	db := newTestDB(t)
+	a, _ := createTask(db, "A: Alpha")
+	b, _ := createTask(db, "B: Beta")
+	for _, it := range []item{
+		{TaskID: a, Text: "a1", Status: Done, FrozenDuration: time.Hour, CheckedAt: &now},
+		{TaskID: a, Text: "a2", Status: Done, FrozenDuration: 30 * time.Minute, CheckedAt: &now},
+		{TaskID: b, Text: "b1", Status: Done, FrozenDuration: 15 * time.Minute, CheckedAt: &now},
+		{TaskID: b, Text: "b2", Status: Started, FrozenDuration: time.Hour},
+	} {
+		saveItem(db, it)
+	}
+	tests := []struct {
+		countDone bool
+		want      []taskTotal
+	}{
+		{true, []taskTotal{
+			{Code: "A", Title: "Alpha", Items: 2, Spent: 90 * time.Minute},
+			{Code: "B", Title: "Beta", Items: 1, Spent: 15 * time.Minute},
+		}},
+		{false, []taskTotal{
+			{Code: "A", Title: "Alpha", Items: 2},
+			{Code: "B", Title: "Beta", Items: 1},
+		}},
+	}
+	defer func(v bool) { countDoneTime = v }(countDoneTime)
+	for _, tt := range tests {
+		countDoneTime = tt.countDone
+		r, err := buildReport(db, "2026-03-02", "2026-03-02", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.totals) != len(tt.want) {
+			t.Fatalf("count_done_time=%v: got %d totals, want %d", tt.countDone, len(r.totals), len(tt.want))
+		}
+		for i := range tt.want {
+			if r.totals[i] != tt.want[i] {
+				t.Errorf("count_done_time=%v: total %d = %+v, want %+v", tt.countDone, i, r.totals[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// resetTask puts every item in the task back to not started with no time
+// on it, keeping the items themselves, their order and their notes.
+func resetTask(db *sql.DB, taskID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := execDB(tx, `UPDATE items SET status = ?, checked_at = '', frozen_duration = 0, duration_seconds = 0,
+		clocked_out = 0, pomodoros = 0, started_at = created_at WHERE task_id = ? AND deleted_at = ''`, NotStarted, taskID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := execDB(tx, "UPDATE tasks SET status = ?, last_activity_at = ? WHERE id = ?", NotStarted, formatStamp(time.Now()), taskID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *model) confirmReset() {
+	t, ok := m.openedTask()
+	if !ok {
+		if t, ok = m.currentTask(); !ok {
+			return
+		}
+	} else if m.taskGone() {
+		return
+	}
+	var changed []item
+	for _, it := range m.store.Items(t.ID) {
+		if it.Status != NotStarted || it.FrozenDuration != 0 || it.Pomodoros != 0 {
+			changed = append(changed, it)
+		}
+	}
+	if len(changed) == 0 {
+		m.setStatus("Nothing to reset in " + t.Code)
+		return
+	}
+	m.confirm = &confirmation{
+		prompt:  fmt.Sprintf("Reset the timers of %d items in %s? y/n", len(changed), t.Code),
+		preview: itemPreview(changed, time.Now()),
+		action: func(m *model) {
+			if err := m.store.ResetTask(t.ID); err != nil {
+				m.setStatus("Reset failed: " + err.Error())
+				return
+			}
+			m.reloadTasks()
+			if m.selectedTaskID != 0 {
+				m.reloadItems()
+			}
+			m.setStatus("Reset " + t.Code)
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestResetTask(t *testing.T) {
+	db := newTestDB(t)
+	id, _ := createTask(db, "Practice")
+	other, _ := createTask(db, "Other")
+	now := time.Now()
+	for i, it := range []item{
+		{Text: "warm up", Status: Done, CheckedAt: &now, FrozenDuration: time.Hour, Note: "keep me"},
+		{Text: "drill", Status: Started, StartedAt: now.Add(-time.Minute), Pomodoros: 2},
+		{Text: "rest", Status: Skipped, FrozenDuration: time.Minute},
+		{Text: "cool down", Status: Started, ClockedOut: true, FrozenDuration: time.Minute},
+	} {
+		it.TaskID, it.Position = id, i+1
+		saveItem(db, it)
+	}
+	saveItem(db, item{TaskID: other, Text: "untouched", Status: Done, CheckedAt: &now, FrozenDuration: time.Hour})
+	updateTaskStatus(db, id)
+
+	if err := resetTask(db, id); err != nil {
+		t.Fatal(err)
+	}
+	items := loadItems(db, id)
+	if got := itemTexts(items); got != "warm up,drill,rest,cool down" {
+		t.Errorf("items after reset = %s, want them all in order", got)
+	}
+	for _, it := range items {
+		if it.Status != NotStarted || it.CheckedAt != nil || it.FrozenDuration != 0 || it.Pomodoros != 0 || it.ClockedOut {
+			t.Errorf("%s not reset: %+v", it.Text, it)
+		}
+	}
+	if items[0].Note != "keep me" {
+		t.Errorf("note lost: %q", items[0].Note)
+	}
+	for _, tk := range loadTasks(db) {
+		if tk.ID == id && tk.Status != NotStarted {
+			t.Errorf("task status = %v, want not started", tk.Status)
+		}
+	}
+	if it := loadItems(db, other)[0]; it.Status != Done || it.FrozenDuration != time.Hour {
+		t.Errorf("other task's item changed: %+v", it)
+	}
+}
+
+func TestResetAsksFirst(t *testing.T) {
+	tests := []struct {
+		answer string
+		reset  bool
+	}{
+		{"n", false},
+		{"y", true},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		id, _ := m.store.CreateTask("Practice")
+		now := time.Now()
+		m.store.SaveItem(item{TaskID: id, Text: "x", Status: Done, CheckedAt: &now, FrozenDuration: time.Hour})
+		m.reloadTasks()
+		m.openTask(id)
+		m = press(m, "\\reset", tea.KeyEnter)
+		if m.confirm == nil {
+			t.Fatalf("answer %s: \\reset did not ask first", tt.answer)
+		}
+		m = press(m, tt.answer)
+		if got := m.store.Items(id)[0].Status == NotStarted; got != tt.reset {
+			t.Errorf("answer %s: reset = %v, want %v", tt.answer, got, tt.reset)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+// dbRetries and dbBackoff bound how long a helper keeps retrying a busy
+// database, set from the db_retries and db_backoff settings whenever the
+// config is loaded. The backoff doubles after every attempt, and no retry
+// starts once dbRetryBudget has passed, since the helpers run on the UI
+// loop.
+var (
+	dbRetries     = 5
+	dbBackoff     = 20 * time.Millisecond
+	dbRetryBudget = time.Second
+)
+
+// withRetry runs fn again while it fails with SQLITE_BUSY, on top of the
+// driver's own busy_timeout, which gives up early on some lock upgrades.
+func withRetry(fn func() error) error {
+	start, wait := time.Now(), dbBackoff
+	err := fn()
+	for i := 0; i < dbRetries && err != nil && isBusy(err); i++ {
+		if time.Since(start)+wait > dbRetryBudget {
+			break
+		}
+		time.Sleep(wait)
+		wait *= 2
+		err = fn()
+	}
+	return classify(err)
+}
+
+func execDB(db querier, query string, args ...any) (sql.Result, error) {
+	if slowLog != nil {
+		defer logSlow(query, time.Now())
+	}
+	var res sql.Result
+	err := withRetry(func() (err error) {
+		res, err = db.Exec(query, args...)
+		return err
+	})
+	return res, err
+}
+
+// dbRows is the result of queryDB. Close logs a slow query, so the time
+// spent reading the rows counts as well as the query itself.
+type dbRows struct {
+	*sql.Rows
+	query string
+	start time.Time
+}
+
+func (r *dbRows) Close() error {
+	err := r.Rows.Close()
+	if slowLog != nil {
+		logSlow(r.query, r.start)
+	}
+	return err
+}
+
+func queryDB(db querier, query string, args ...any) (*dbRows, error) {
+	start := time.Now()
+	var rows *sql.Rows
+	err := withRetry(func() (err error) {
+		rows, err = db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		if slowLog != nil {
+			logSlow(query, start)
+		}
+		return nil, err
+	}
+	return &dbRows{Rows: rows, query: query, start: start}, nil
+}
+
+// retryRow defers the query to Scan, where sql.Row reports its error.
+type retryRow struct {
+	db    querier
+	query string
+	args  []any
+}
+
+func queryRowDB(db querier, query string, args ...any) retryRow {
+	return retryRow{db: db, query: query, args: args}
+}
+
+func (r retryRow) Scan(dest ...any) error {
+	if slowLog != nil {
+		defer logSlow(r.query, time.Now())
+	}
+	return withRetry(func() error {
+		return r.db.QueryRow(r.query, r.args...).Scan(dest...)
+	})
+}
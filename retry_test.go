@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lockedDB returns a second handle on a file database with no busy_timeout
+// of its own, and a function that holds a write lock from the first for d.
+func lockedDB(t *testing.T) (*sql.DB, func(d time.Duration)) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "busy.db")
+	owner := mustOpenDB(path)
+	t.Cleanup(func() { owner.Close() })
+	other, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { other.Close() })
+	hold := func(d time.Duration) {
+		conn, err := owner.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(context.Background(), "BEGIN IMMEDIATE"); err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			time.Sleep(d)
+			conn.ExecContext(context.Background(), "ROLLBACK")
+			conn.Close()
+		}()
+	}
+	return other, hold
+}
+
+func TestRetryOutlastsShortLock(t *testing.T) {
+	db, hold := lockedDB(t)
+	hold(60 * time.Millisecond)
+	if _, err := execDB(db, "INSERT INTO settings (key, value) VALUES ('k', 'v')"); err != nil {
+		t.Fatalf("write under a short lock failed: %v", err)
+	}
+}
+
+func TestRetryGivesUpWithinBudget(t *testing.T) {
+	defer func(budget time.Duration) { dbRetryBudget = budget }(dbRetryBudget)
+	dbRetryBudget = 200 * time.Millisecond
+	db, hold := lockedDB(t)
+	hold(2 * time.Second)
+	start := time.Now()
+	_, err := execDB(db, "INSERT INTO settings (key, value) VALUES ('k', 'v')")
+	if !errors.Is(err, ErrBusy) {
+		t.Errorf("err = %v, want ErrBusy", err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("gave up after %s, want under the budget", took)
+	}
+}
+
+func TestLoadersSurviveAClosedDB(t *testing.T) {
+	db := newTestDB(t)
+	createTask(db, "a")
+	db.Close()
+	if tasks := loadTasks(db); len(tasks) != 0 {
+		t.Errorf("loadTasks on a closed db = %v", tasks)
+	}
+	if items := loadItems(db, 1); len(items) != 0 {
+		t.Errorf("loadItems on a closed db = %v", items)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type session struct {
+	start    time.Time
+	lastTick time.Time
+	active   time.Duration
+}
+
+func newSession(now time.Time) session {
+	return session{start: now, lastTick: now}
+}
+
+// tickSession credits the time since the last tick as active when any item
+// is running, so active time stays within a tick of the real figure.
+func (m *model) tickSession(now time.Time) {
+	if len(m.store.RunningItems()) > 0 {
+		m.session.active += now.Sub(m.session.lastTick)
+	}
+	m.session.lastTick = now
+}
+
+func (s session) String() string {
+	return fmt.Sprintf("session %s (%s active)", formatDuration(s.lastTick.Sub(s.start)), formatDuration(s.active))
+}
+
+// live is what View shows that takes a query to work out. Update refreshes
+// it after every message, the one-second tick included, so View never
+// touches the database.
+type live struct {
+	pace    string
+	compact string
+	goal    string
+}
+
+func (m *model) refreshLive(now time.Time) {
+	if !m.hideFooter {
+		m.live.pace = paceLabel(m.store.Completions(paceFrom(m.session.start, now)), m.session.start, now)
+	}
+	if m.compact {
+		m.live.compact = m.compactLine(now)
+	}
+	m.live.goal = ""
+	if t, ok := m.openedTask(); ok && t.DailyGoal > 0 {
+		m.live.goal = goalLabel(t, m.store.WorkedItems(t.ID), now)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCompletionsFiltersInSQL(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	db := newTestDB(t)
+	id, _ := createTask(db, "Pace")
+	for _, ago := range []time.Duration{10 * time.Minute, 50 * time.Minute, 2 * time.Hour, 48 * time.Hour} {
+		at := now.Add(-ago)
+		saveItem(db, item{TaskID: id, Text: "x", Status: Done, CheckedAt: &at})
+	}
+	tests := []struct {
+		since time.Duration
+		want  int
+	}{
+		{15 * time.Minute, 1},
+		{time.Hour, 2},
+		{3 * time.Hour, 3},
+		{72 * time.Hour, 4},
+	}
+	for _, tt := range tests {
+		if got := len(loadCompletions(db, now.Add(-tt.since))); got != tt.want {
+			t.Errorf("since %v ago: %d completions, want %d", tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestViewDoesNotQuery(t *testing.T) {
+	db := newTestDB(t)
+	id, _ := createTask(db, "Live")
+	saveItem(db, item{TaskID: id, Text: "running", Status: Started, StartedAt: time.Now()})
+	setTaskGoal(db, id, time.Hour)
+	m := newModel(newStore(db))
+	m.openTask(id)
+	m.compact = true
+	m.refreshLive(time.Now())
+	// With the database gone, anything View still read from it would come
+	// back empty.
+	db.Close()
+	for _, tt := range []struct {
+		name    string
+		compact bool
+		want    string
+	}{
+		{"footer pace", false, "pace:"},
+		{"compact line", true, "T01 0/1 [>] running"},
+		{"daily goal", false, " / 1h0m0s today"},
+	} {
+		m.compact = tt.compact
+		if got := m.View(); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: view lost %q after the database closed:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type config struct {
+	SaveDebounce     time.Duration
+	ConfirmThreshold int
+	DoneLast         bool
+	NotifyOverrun    bool
+	Dashboard        bool
+	LegacyEnter      bool
+	Numbered         bool
+	CloseOnComplete  bool
+	PriorityFirst    bool
+	TaskSort         taskSort
+	SortDesc         bool
+	ShowActivity     bool
+	BackupInterval   time.Duration
+	BackupKeep       int
+	CheckOrphans     bool
+	HideTimers       bool
+	HideFooter       bool
+	Modal            bool
+	PomodoroWork     time.Duration
+	PomodoroBreak    time.Duration
+	Rounding         time.Duration
+	CountDoneTime    bool
+	NoteOnDone       bool
+	GroupByStatus    bool
+	TaskWrap         bool
+	QuickDelete      bool
+	DBRetries        int
+	DBBackoff        time.Duration
+	AgeColors        bool
+	AgeWarnDays      int
+	AgeStaleDays     int
+	MaxTasks         int
+	MaxItems         int
+}
+
+func defaultConfig() config {
+	return config{
+		SaveDebounce:     300 * time.Millisecond,
+		ConfirmThreshold: 3,
+		BackupInterval:   24 * time.Hour,
+		BackupKeep:       7,
+		PomodoroWork:     25 * time.Minute,
+		PomodoroBreak:    5 * time.Minute,
+		Rounding:         time.Second,
+		CountDoneTime:    true,
+		DBRetries:        5,
+		DBBackoff:        20 * time.Millisecond,
+		AgeColors:        true,
+		AgeWarnDays:      3,
+		AgeStaleDays:     7,
+		MaxTasks:         100,
+		MaxItems:         200,
+	}
+}
+
+func loadConfig(s Storage) config {
+	cfg := defaultConfig()
+	if d, err := time.ParseDuration(s.Setting("save_debounce")); err == nil && d >= 0 {
+		cfg.SaveDebounce = d
+	}
+	if n, err := strconv.Atoi(s.Setting("confirm_threshold")); err == nil && n >= 0 {
+		cfg.ConfirmThreshold = n
+	}
+	if d, err := time.ParseDuration(s.Setting("backup_interval")); err == nil && d >= 0 {
+		cfg.BackupInterval = d
+	}
+	if d, err := time.ParseDuration(s.Setting("pomodoro_work")); err == nil && d > 0 {
+		cfg.PomodoroWork = d
+	}
+	if d, err := time.ParseDuration(s.Setting("pomodoro_break")); err == nil && d > 0 {
+		cfg.PomodoroBreak = d
+	}
+	if n, err := strconv.Atoi(s.Setting("db_retries")); err == nil && n >= 0 {
+		cfg.DBRetries = n
+	}
+	if d, err := time.ParseDuration(s.Setting("db_backoff")); err == nil && d > 0 {
+		cfg.DBBackoff = d
+	}
+	if n, err := strconv.Atoi(s.Setting("backup_keep")); err == nil && n > 0 {
+		cfg.BackupKeep = n
+	}
+	if n, err := strconv.Atoi(s.Setting("age_warn_days")); err == nil && n > 0 {
+		cfg.AgeWarnDays = n
+	}
+	if n, err := strconv.Atoi(s.Setting("age_stale_days")); err == nil && n > 0 {
+		cfg.AgeStaleDays = n
+	}
+	if n, err := strconv.Atoi(s.Setting("max_tasks")); err == nil && n >= 0 {
+		cfg.MaxTasks = n
+	}
+	if n, err := strconv.Atoi(s.Setting("max_items")); err == nil && n >= 0 {
+		cfg.MaxItems = n
+	}
+	if b, err := strconv.ParseBool(s.Setting("age_colors")); err == nil {
+		cfg.AgeColors = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("done_last")); err == nil {
+		cfg.DoneLast = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("notify_overrun")); err == nil {
+		cfg.NotifyOverrun = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("dashboard")); err == nil {
+		cfg.Dashboard = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("legacy_enter")); err == nil {
+		cfg.LegacyEnter = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("numbered")); err == nil {
+		cfg.Numbered = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("close_on_complete")); err == nil {
+		cfg.CloseOnComplete = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("priority_first")); err == nil {
+		cfg.PriorityFirst = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("show_activity")); err == nil {
+		cfg.ShowActivity = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("group_by_status")); err == nil {
+		cfg.GroupByStatus = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("quick_delete")); err == nil {
+		cfg.QuickDelete = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("task_wrap")); err == nil {
+		cfg.TaskWrap = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("note_on_done")); err == nil {
+		cfg.NoteOnDone = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("modal")); err == nil {
+		cfg.Modal = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("hide_timers")); err == nil {
+		cfg.HideTimers = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("hide_footer")); err == nil {
+		cfg.HideFooter = b
+	}
+	if b, err := strconv.ParseBool(s.Setting("check_orphans")); err == nil {
+		cfg.CheckOrphans = b
+	}
+	if d, ok := parseRounding(s.Setting("duration_rounding")); ok {
+		cfg.Rounding = d
+	}
+	if b, err := strconv.ParseBool(s.Setting("count_done_time")); err == nil {
+		cfg.CountDoneTime = b
+	}
+	displayRounding = cfg.Rounding
+	countDoneTime = cfg.CountDoneTime
+	dbRetries, dbBackoff = cfg.DBRetries, cfg.DBBackoff
+	if by, desc, ok := parseTaskSort(s.Setting("task_sort")); ok {
+		cfg.TaskSort, cfg.SortDesc = by, desc
+	}
+	return cfg
+}
+
+func parseRounding(s string) (time.Duration, bool) {
+	switch s {
+	case "":
+		return 0, false
+	case "exact":
+		return 0, true
+	case "second":
+		return time.Second, true
+	case "minute":
+		return time.Minute, true
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil && d > 0
+}
+
+func getSetting(db querier, key string) string {
+	var value string
+	queryRowDB(db, "SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	return value
+}
+
+func setSetting(db querier, key, value string) {
+	execDB(db, "INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", key, value)
+}
+
+type viewState struct {
+	TaskID int64
+	Cursor int
+}
+
+type saveStateMsg struct {
+	seq int
+}
+
+func (m model) viewState() viewState {
+	return viewState{TaskID: m.selectedTaskID, Cursor: m.cursor}
+}
+
+func loadViewState(s Storage) viewState {
+	var v viewState
+	v.TaskID, _ = strconv.ParseInt(s.Setting("view.task_id"), 10, 64)
+	v.Cursor, _ = strconv.Atoi(s.Setting("view.cursor"))
+	return v
+}
+
+func saveViewState(s Storage, v viewState) {
+	s.SetSetting("view.task_id", strconv.FormatInt(v.TaskID, 10))
+	s.SetSetting("view.cursor", strconv.Itoa(v.Cursor))
+}
+
+func (m *model) scheduleSave() tea.Cmd {
+	m.saveSeq++
+	seq := m.saveSeq
+	return tea.Tick(m.cfg.SaveDebounce, func(time.Time) tea.Msg {
+		return saveStateMsg{seq: seq}
+	})
+}
+
+const statusTTL = 4 * time.Second
+
+type clearStatusMsg struct {
+	seq int
+}
+
+func (m *model) setStatus(s string) {
+	m.status = s
+	m.statusSeq++
+}
+
+func (m model) clearStatusLater() tea.Cmd {
+	seq := m.statusSeq
+	return tea.Tick(statusTTL, func(time.Time) tea.Msg {
+		return clearStatusMsg{seq: seq}
+	})
+}
+
+func (m model) quit() tea.Cmd {
+	saveViewState(m.store, m.viewState())
+	return tea.Quit
+}
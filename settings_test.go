@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDurations(t *testing.T) {
+	def := defaultConfig()
+	// loadConfig sets the package-level rounding and retry values too.
+	t.Cleanup(func() { loadConfig(newStore(newTestDB(t))) })
+	tests := []struct {
+		key, value string
+		get        func(config) time.Duration
+		want       time.Duration
+	}{
+		{"save_debounce", "300ms", func(c config) time.Duration { return c.SaveDebounce }, 300 * time.Millisecond},
+		{"save_debounce", "300", func(c config) time.Duration { return c.SaveDebounce }, def.SaveDebounce},
+		{"db_backoff", "50ms", func(c config) time.Duration { return c.DBBackoff }, 50 * time.Millisecond},
+		{"db_backoff", "50", func(c config) time.Duration { return c.DBBackoff }, def.DBBackoff},
+		{"pomodoro_work", "25m", func(c config) time.Duration { return c.PomodoroWork }, 25 * time.Minute},
+		{"pomodoro_work", "25", func(c config) time.Duration { return c.PomodoroWork }, def.PomodoroWork},
+		{"backup_interval", "1 hour 30", func(c config) time.Duration { return c.BackupInterval }, def.BackupInterval},
+		{"duration_rounding", "5", func(c config) time.Duration { return c.Rounding }, def.Rounding},
+		{"duration_rounding", "5m", func(c config) time.Duration { return c.Rounding }, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		s := newStore(newTestDB(t))
+		s.SetSetting(tt.key, tt.value)
+		if got := tt.get(loadConfig(s)); got != tt.want {
+			t.Errorf("%s = %q: got %s, want %s", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
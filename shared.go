@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// sharedTimer runs several items from one start and stops them together.
+// With split set the shared span is divided evenly between the items
+// still running when it stops; otherwise each gets all of it. It lives
+// only in the model, so after a restart the items just run on their own.
+type sharedTimer struct {
+	start time.Time
+	ids   []int64
+	split bool
+}
+
+// startShared puts every item on the clock from now, on top of the time
+// it already has.
+func startShared(items []item, now time.Time) {
+	for i := range items {
+		items[i].Status = Started
+		items[i].CheckedAt = nil
+		items[i].StartedAt = now.Add(-items[i].FrozenDuration)
+		items[i].ClockedOut = false
+	}
+}
+
+// stopShared clocks out the items still running from the shared start and
+// returns them. Items that were stopped, completed or restarted on their
+// own since then have left the group and are not touched.
+func stopShared(items []item, s sharedTimer, now time.Time) []item {
+	var running []item
+	for _, it := range items {
+		if it.Status == Started && !it.ClockedOut && !it.StartedAt.After(s.start) {
+			running = append(running, it)
+		}
+	}
+	span := now.Sub(s.start)
+	if s.split && len(running) > 0 {
+		span /= time.Duration(len(running))
+	}
+	for i := range running {
+		running[i].FrozenDuration = s.start.Sub(running[i].StartedAt) + span
+		running[i].ClockedOut = true
+	}
+	return running
+}
+
+func (m *model) toggleShared(arg string) {
+	now := time.Now()
+	if m.shared != nil {
+		var items []item
+		for _, id := range m.shared.ids {
+			if it, ok := m.store.Item(id); ok {
+				items = append(items, it)
+			}
+		}
+		stopped := stopShared(items, *m.shared, now)
+		for _, it := range stopped {
+			m.store.SaveItemStatus(it)
+			m.store.UpdateTaskStatus(it.TaskID)
+		}
+		m.shared = nil
+		m.reloadItems()
+		m.setStatus(fmt.Sprintf("Stopped the shared timer on %d items", len(stopped)))
+		return
+	}
+	if m.selectedTaskID == 0 || m.taskGone() {
+		return
+	}
+	if arg != "" && arg != "split" {
+		m.setStatus("Usage: \\shared [split]")
+		return
+	}
+	items := m.markedItems()
+	if len(items) < 2 {
+		m.setStatus("Mark at least two items with ctrl+x to share a timer")
+		return
+	}
+	startShared(items, now)
+	s := &sharedTimer{start: now, split: arg == "split"}
+	for _, it := range items {
+		m.store.SaveItemStatus(it)
+		s.ids = append(s.ids, it.ID)
+	}
+	m.store.UpdateTaskStatus(m.selectedTaskID)
+	m.shared = s
+	m.marked = nil
+	m.reloadItems()
+	m.setStatus(fmt.Sprintf("Started a shared timer on %d items, \\shared to stop", len(items)))
+}
+
+func (m model) sharedLine(now time.Time) string {
+	if m.shared == nil {
+		return ""
+	}
+	how := "each"
+	if m.shared.split {
+		how = "split"
+	}
+	return fmt.Sprintf("Shared timer %s on %d items (%s)\n\n", formatDuration(now.Sub(m.shared.start)), len(m.shared.ids), how)
+}
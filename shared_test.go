@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSharedTimer(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		split bool
+		left  bool // the last item is restarted on its own mid-way
+		want  []time.Duration
+	}{
+		{"each gets the whole span", false, false, []time.Duration{40 * time.Minute, 30 * time.Minute, 30 * time.Minute}},
+		{"split evenly", true, false, []time.Duration{20 * time.Minute, 10 * time.Minute, 10 * time.Minute}},
+		{"split between those still in the group", true, true, []time.Duration{25 * time.Minute, 15 * time.Minute}},
+	}
+	for _, tt := range tests {
+		items := []item{
+			{ID: 1, FrozenDuration: 10 * time.Minute},
+			{ID: 2},
+			{ID: 3, Status: Done},
+		}
+		startShared(items, start)
+		for _, it := range items {
+			if it.Status != Started || it.ClockedOut || it.CheckedAt != nil || !it.StartedAt.Add(it.FrozenDuration).Equal(start) {
+				t.Fatalf("%s: started %+v, want running from the shared start", tt.name, it)
+			}
+		}
+		if tt.left {
+			items[2].StartedAt = start.Add(5 * time.Minute)
+		}
+		stopped := stopShared(items, sharedTimer{start: start, ids: []int64{1, 2, 3}, split: tt.split}, start.Add(30*time.Minute))
+		if len(stopped) != len(tt.want) {
+			t.Fatalf("%s: stopped %d items, want %d", tt.name, len(stopped), len(tt.want))
+		}
+		for i, it := range stopped {
+			if it.FrozenDuration != tt.want[i] || !it.ClockedOut {
+				t.Errorf("%s: item %d has %v clocked out %v, want %v clocked out", tt.name, it.ID, it.FrozenDuration, it.ClockedOut, tt.want[i])
+			}
+		}
+	}
+}
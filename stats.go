@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type stats struct {
+	AvgPerItem     time.Duration
+	ItemsPerTask   float64
+	DoneThisWeek   int
+	Longest        itemWithTask
+	CompletionRate float64
+}
+
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// computeStats aggregates the loaded items; weeks start on Monday and
+// skipped items only count towards items per task.
+func computeStats(tasks []task, all []itemWithTask, now time.Time) stats {
+	var s stats
+	var spent time.Duration
+	week := startOfWeek(now)
+	items := make([]item, len(all))
+	for i, it := range all {
+		items[i] = it.item
+	}
+	done, counted := countDone(items)
+	for _, it := range all {
+		if it.Status == Skipped {
+			continue
+		}
+		if it.Status == Done {
+			spent += it.FrozenDuration
+			if it.CheckedAt != nil && !it.CheckedAt.Before(week) {
+				s.DoneThisWeek++
+			}
+		}
+		if it.elapsed(now) > s.Longest.elapsed(now) {
+			s.Longest = it
+		}
+	}
+	if done > 0 {
+		s.AvgPerItem = spent / time.Duration(done)
+	}
+	if len(tasks) > 0 {
+		s.ItemsPerTask = float64(len(items)) / float64(len(tasks))
+	}
+	if counted > 0 {
+		s.CompletionRate = float64(done) / float64(counted)
+	}
+	return s
+}
+
+func (m *model) openStats() {
+	items, err := m.store.AllItemsWithTask()
+	if err != nil {
+		m.setStatus("Stats failed: " + err.Error())
+		return
+	}
+	s := computeStats(m.store.Tasks(), items, time.Now())
+	m.stats = &s
+}
+
+func (m model) updateStats(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, m.quit()
+	}
+	m.stats = nil
+	return m, nil
+}
+
+func (m model) viewStats(b *strings.Builder) {
+	s := m.stats
+	now := time.Now()
+	longest := "none"
+	if s.Longest.ID != 0 {
+		longest = fmt.Sprintf("%s (%s)", s.Longest, formatDuration(s.Longest.elapsed(now)))
+	}
+	b.WriteString("Stats\n\n")
+	if m.stats.ItemsPerTask == 0 {
+		b.WriteString(m.emptyState("No items to summarize yet"))
+		b.WriteString("\nany key to go back")
+		return
+	}
+	b.WriteString(fmt.Sprintf("Average time per completed item  %s\n", formatDuration(s.AvgPerItem)))
+	b.WriteString(fmt.Sprintf("Average items per task           %.1f\n", s.ItemsPerTask))
+	b.WriteString(fmt.Sprintf("Completed this week              %d\n", s.DoneThisWeek))
+	b.WriteString(fmt.Sprintf("Longest-running item             %s\n", longest))
+	b.WriteString(fmt.Sprintf("Completion rate                  %.0f%%\n", s.CompletionRate*100))
+	b.WriteString("\nany key to go back")
+}
+
+const (
+	paceWindow = time.Hour
+	paceMinGap = 5 * time.Minute
+	paceMinN   = 2
+)
+
+// pace is completions per hour over the last paceWindow, or since the
+// session started if that is more recent. It reports false until there
+// are paceMinN completions over at least paceMinGap.
+func pace(completions []time.Time, sessionStart, now time.Time) (float64, bool) {
+	from := paceFrom(sessionStart, now)
+	n := 0
+	for _, t := range completions {
+		if !t.Before(from) && !t.After(now) {
+			n++
+		}
+	}
+	window := now.Sub(from)
+	if n < paceMinN || window < paceMinGap {
+		return 0, false
+	}
+	return float64(n) / window.Hours(), true
+}
+
+// paceFrom is where the pace window starts.
+func paceFrom(sessionStart, now time.Time) time.Time {
+	from := now.Add(-paceWindow)
+	if sessionStart.After(from) {
+		return sessionStart
+	}
+	return from
+}
+
+func paceLabel(completions []time.Time, sessionStart, now time.Time) string {
+	p, ok := pace(completions, sessionStart, now)
+	if !ok {
+		return "pace: —"
+	}
+	return fmt.Sprintf("pace: %.1f items/hr", p)
+}
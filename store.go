@@ -0,0 +1,306 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store errors wrap the driver's error where there is one, so errors.Is
+// tells them apart and the message keeps the detail.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already in use")
+	ErrBusy     = errors.New("database is busy")
+)
+
+// classify wraps a driver error in the store error it amounts to; other
+// errors come back unchanged.
+func classify(err error) error {
+	switch {
+	case err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrBusy):
+		return err
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case strings.Contains(err.Error(), "constraint failed"):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case isBusy(err):
+		return fmt.Errorf("%w: %w", ErrBusy, err)
+	}
+	return err
+}
+
+// errorCode names the store error for scripts, empty for anything else.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrConflict):
+		return "conflict"
+	case errors.Is(err, ErrBusy):
+		return "busy"
+	}
+	return ""
+}
+
+// Storage is all the database access the model goes through, so it can run
+// against something other than SQLite. Command-line modes that run without
+// the model still call the helpers directly.
+type Storage interface {
+	Tasks() []task
+	CreateTask(input string) (int64, error)
+	UpdateTask(taskID int64, input string) error
+	DeleteTask(taskID int64) error
+	SetTaskStatus(taskID int64, s itemStatus)
+	UpdateTaskStatus(taskID int64) bool
+	ReconcileTaskStatus(taskID int64) bool
+	FixTaskStatuses() int
+	TaskExists(taskID int64) bool
+	ResolveTask(c command) (task, error)
+	TaskProgress(taskID int64) (done, total int)
+	AllDone(taskID int64) bool
+	InboxTaskID() int64
+	SetTaskTitle(taskID int64, title string)
+	SetTaskDue(taskID int64, due *time.Time)
+	SetTaskGoal(taskID int64, d time.Duration)
+	SetTaskDescription(taskID int64, desc string)
+	SetTaskRef(taskID int64, ref string)
+	ArchiveTasks(ids []int64) (int, error)
+	UnarchiveTask(code string) error
+	ResetTask(taskID int64) error
+	CarryOver(fromTaskID int64, title string) (int64, int, error)
+	LoadChecklistFile(path, title string) (int64, error)
+
+	Items(taskID int64) []item
+	Item(itemID int64) (item, bool)
+	AllItems() []item
+	AllItemsWithTask() ([]itemWithTask, error)
+	RunningItems() []item
+	WorkedItems(taskID int64) []item
+	SaveItem(it item) int64
+	SaveItemStatus(it item)
+	UpdateItemText(itemID int64, text string)
+	DeleteItem(itemID int64) error
+	InsertItemAfter(after, it item) int64
+	CopyItems(fromTaskID, toTaskID int64) (int, error)
+	SetItemPositions(items []item) error
+	SetItemEstimate(itemID int64, d time.Duration)
+	SetItemNote(itemID int64, note string)
+	SetItemPriority(itemID int64, high bool)
+	SetItemRef(itemID int64, ref string)
+	AddPomodoro(itemID int64)
+
+	Setting(key string) string
+	SetSetting(key, value string)
+	Pause() (at time.Time, ids []int64, ok bool)
+	SavePause(at time.Time, ids []int64)
+	ClearPause()
+	Templates() map[string]string
+
+	Completions(since time.Time) []time.Time
+	Report(fromArg, toArg string, now time.Time) (report, error)
+	DailyCSV() ([]byte, error)
+	Trash() []trashEntry
+	RestoreEntry(e trashEntry) error
+	PurgeEntry(e trashEntry) error
+	Orphans() ([]item, error)
+	DeleteOrphans() (int64, error)
+	RecoverOrphans() (int64, error)
+
+	BackupPrefix() string
+	Backup(path string) error
+	SaveAs(path string) error
+}
+
+// Store is the SQLite Storage, a thin layer over the existing helpers. It is
+// a querier itself: each query is prepared the first time it runs and the
+// statement reused after that, so the helpers it passes itself to skip
+// parsing the SQL again on every keypress and tick.
+type Store struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStore(db *sql.DB) *Store {
+	return &Store{db: db, stmts: map[string]*sql.Stmt{}}
+}
+
+func (s *Store) prepare(query string) (*sql.Stmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (s *Store) Exec(query string, args ...any) (sql.Result, error) {
+	stmt, err := s.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
+
+func (s *Store) Query(query string, args ...any) (*sql.Rows, error) {
+	stmt, err := s.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// QueryRow falls back to the database when the statement does not prepare,
+// so the error still surfaces from Scan.
+func (s *Store) QueryRow(query string, args ...any) *sql.Row {
+	stmt, err := s.prepare(query)
+	if err != nil {
+		return s.db.QueryRow(query, args...)
+	}
+	return stmt.QueryRow(args...)
+}
+
+func (s *Store) Tasks() []task { return loadTasks(s) }
+
+func (s *Store) CreateTask(input string) (int64, error) { return createTask(s, input) }
+
+func (s *Store) UpdateTask(taskID int64, input string) error { return updateTask(s, taskID, input) }
+
+func (s *Store) DeleteTask(taskID int64) error { return deleteTask(s, taskID) }
+
+func (s *Store) SetTaskStatus(taskID int64, st itemStatus) { setTaskStatus(s, taskID, st) }
+
+func (s *Store) UpdateTaskStatus(taskID int64) bool { return updateTaskStatus(s, taskID) }
+
+func (s *Store) ReconcileTaskStatus(taskID int64) bool { return reconcileTaskStatus(s, taskID) }
+
+func (s *Store) FixTaskStatuses() int { return fixTaskStatuses(s) }
+
+func (s *Store) TaskExists(taskID int64) bool { return taskExists(s, taskID) }
+
+func (s *Store) ResolveTask(c command) (task, error) { return resolveTask(s, c) }
+
+func (s *Store) TaskProgress(taskID int64) (done, total int) { return taskProgress(s, taskID) }
+
+func (s *Store) AllDone(taskID int64) bool { return allDone(s, taskID) }
+
+func (s *Store) InboxTaskID() int64 { return inboxTaskID(s) }
+
+func (s *Store) SetTaskTitle(taskID int64, title string) { setTaskTitle(s, taskID, title) }
+
+func (s *Store) SetTaskDue(taskID int64, due *time.Time) { setTaskDue(s, taskID, due) }
+
+func (s *Store) SetTaskGoal(taskID int64, d time.Duration) { setTaskGoal(s, taskID, d) }
+
+func (s *Store) SetTaskDescription(taskID int64, desc string) {
+	setTaskDescription(s, taskID, desc)
+}
+
+func (s *Store) SetTaskRef(taskID int64, ref string) { setTaskRef(s, taskID, ref) }
+
+func (s *Store) ArchiveTasks(ids []int64) (int, error) { return archiveTasks(s.db, ids) }
+
+func (s *Store) UnarchiveTask(code string) error { return unarchiveTask(s, code) }
+
+func (s *Store) ResetTask(taskID int64) error { return resetTask(s.db, taskID) }
+
+func (s *Store) CarryOver(fromTaskID int64, title string) (int64, int, error) {
+	return carryOver(s.db, fromTaskID, title)
+}
+
+func (s *Store) LoadChecklistFile(path, title string) (int64, error) {
+	return loadChecklistFile(s.db, path, title)
+}
+
+func (s *Store) Items(taskID int64) []item { return loadItems(s, taskID) }
+
+func (s *Store) Item(itemID int64) (item, bool) {
+	items := queryItems(s, "WHERE id = ? AND deleted_at = ''", itemID)
+	if len(items) == 0 {
+		return item{}, false
+	}
+	return items[0], true
+}
+
+func (s *Store) AllItems() []item {
+	return queryItems(s, "WHERE deleted_at = '' ORDER BY task_id, position, id")
+}
+
+func (s *Store) AllItemsWithTask() ([]itemWithTask, error) { return loadAllItemsWithTask(s) }
+
+func (s *Store) RunningItems() []item { return loadRunningItems(s) }
+
+func (s *Store) WorkedItems(taskID int64) []item { return loadWorkedItems(s, taskID) }
+
+func (s *Store) SaveItem(it item) int64 { return saveItem(s, it) }
+
+func (s *Store) SaveItemStatus(it item) { saveItemStatus(s, it) }
+
+func (s *Store) UpdateItemText(itemID int64, text string) { updateItemText(s, itemID, text) }
+
+func (s *Store) DeleteItem(itemID int64) error { return deleteItem(s, itemID) }
+
+func (s *Store) InsertItemAfter(after, it item) int64 { return insertItemAfter(s, after, it) }
+
+func (s *Store) CopyItems(fromTaskID, toTaskID int64) (int, error) {
+	return copyItems(s.db, fromTaskID, toTaskID)
+}
+
+func (s *Store) SetItemPositions(items []item) error { return setItemPositions(s.db, items) }
+
+func (s *Store) SetItemEstimate(itemID int64, d time.Duration) { setItemEstimate(s, itemID, d) }
+
+func (s *Store) SetItemNote(itemID int64, note string) { setItemNote(s, itemID, note) }
+
+func (s *Store) SetItemPriority(itemID int64, high bool) { setItemPriority(s, itemID, high) }
+
+func (s *Store) SetItemRef(itemID int64, ref string) { setItemRef(s, itemID, ref) }
+
+func (s *Store) AddPomodoro(itemID int64) { addPomodoro(s, itemID) }
+
+func (s *Store) Setting(key string) string { return getSetting(s, key) }
+
+func (s *Store) SetSetting(key, value string) { setSetting(s, key, value) }
+
+func (s *Store) Pause() (time.Time, []int64, bool) { return loadPause(s) }
+
+func (s *Store) SavePause(at time.Time, ids []int64) { savePause(s, at, ids) }
+
+func (s *Store) ClearPause() { clearPause(s) }
+
+func (s *Store) Templates() map[string]string { return loadTemplates(s) }
+
+func (s *Store) Completions(since time.Time) []time.Time { return loadCompletions(s, since) }
+
+func (s *Store) Report(fromArg, toArg string, now time.Time) (report, error) {
+	return buildReport(s, fromArg, toArg, now)
+}
+
+func (s *Store) DailyCSV() ([]byte, error) { return exportDailyCSV(s) }
+
+func (s *Store) Trash() []trashEntry { return loadTrash(s) }
+
+func (s *Store) RestoreEntry(e trashEntry) error { return restoreEntry(s, e) }
+
+func (s *Store) PurgeEntry(e trashEntry) error { return purgeEntry(s, e) }
+
+func (s *Store) Orphans() ([]item, error) { return findOrphans(s) }
+
+func (s *Store) DeleteOrphans() (int64, error) { return deleteOrphans(s) }
+
+func (s *Store) RecoverOrphans() (int64, error) { return recoverOrphans(s) }
+
+func (s *Store) BackupPrefix() string { return backupPrefix(s.db) }
+
+func (s *Store) Backup(path string) error { return backupDB(s.db, path) }
+
+func (s *Store) SaveAs(path string) error { return saveDBAs(s.db, path) }
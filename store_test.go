@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRefreshLeavesActivityAlone(t *testing.T) {
+	tests := []struct {
+		name   string
+		status itemStatus
+		want   itemStatus
+	}{
+		{"status already right", NotStarted, NotStarted},
+		{"stale status is fixed", Done, NotStarted},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		m := newModel(newStore(db))
+		id, err := m.store.CreateTask("Refresh")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.store.SaveItem(item{TaskID: id, Text: "x"})
+		execDB(db, "UPDATE tasks SET status = ?, last_activity_at = '' WHERE id = ?", tt.status, id)
+		m.reloadTasks()
+		m.openTask(id)
+		m = press(m, tea.KeyF5)
+		var activity string
+		queryRowDB(db, "SELECT last_activity_at FROM tasks WHERE id = ?", id).Scan(&activity)
+		if activity != "" {
+			t.Errorf("%s: refresh stamped last_activity_at = %q", tt.name, activity)
+		}
+		if got := m.store.Tasks()[0].Status; got != tt.want {
+			t.Errorf("%s: status = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// countingStore is a Storage that counts what goes through it, standing in
+// for a backend other than SQLite.
+type countingStore struct {
+	*Store
+	saved, statuses int
+}
+
+func (s *countingStore) SaveItem(it item) int64 { s.saved++; return s.Store.SaveItem(it) }
+
+func (s *countingStore) SaveItemStatus(it item) { s.statuses++; s.Store.SaveItemStatus(it) }
+
+func TestModelRunsOnAnyStorage(t *testing.T) {
+	tests := []struct {
+		name              string
+		keys              []any
+		saved, statuses   int
+		wantItems         int
+		wantStatusOfFirst itemStatus
+	}{
+		{"add an item", []any{"milk", tea.KeyEnter}, 1, 0, 1, NotStarted},
+		{"add and start it", []any{"milk", tea.KeyEnter, " "}, 1, 1, 1, Started},
+		{"add two", []any{"milk", tea.KeyEnter, "jam", tea.KeyEnter}, 2, 0, 2, NotStarted},
+	}
+	for _, tt := range tests {
+		s := &countingStore{Store: newStore(newTestDB(t))}
+		id, err := s.CreateTask("Shopping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := newModel(s)
+		m.openTask(id)
+		m = press(m, tt.keys...)
+		if s.saved != tt.saved || s.statuses != tt.statuses {
+			t.Errorf("%s: %d saves and %d status saves went through the store, want %d and %d", tt.name, s.saved, s.statuses, tt.saved, tt.statuses)
+		}
+		items := s.Items(id)
+		if len(items) != tt.wantItems || items[0].Status != tt.wantStatusOfFirst {
+			t.Errorf("%s: items = %+v, want %d with the first %v", tt.name, items, tt.wantItems, tt.wantStatusOfFirst)
+		}
+	}
+}
+
+func TestStoreReusesPreparedStatements(t *testing.T) {
+	s := newStore(newTestDB(t))
+	id, err := s.CreateTask("Prepared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{"task exists", func() { s.TaskExists(id) }},
+		{"progress", func() { s.TaskProgress(id) }},
+		{"items", func() { s.Items(id) }},
+		{"setting", func() { s.SetSetting("k", "v"); s.Setting("k") }},
+		{"running items", func() { s.RunningItems() }},
+	}
+	for _, tt := range tests {
+		tt.run()
+		before := len(s.stmts)
+		for range 3 {
+			tt.run()
+		}
+		if after := len(s.stmts); after != before {
+			t.Errorf("%s: %d statements after repeating, want %d", tt.name, after, before)
+		}
+	}
+	if len(s.stmts) == 0 {
+		t.Error("no statements were prepared")
+	}
+}
+
+func TestStoreSurfacesBadSQL(t *testing.T) {
+	s := newStore(newTestDB(t))
+	if _, err := execDB(s, "UPDATE nowhere SET x = 1"); err == nil {
+		t.Error("Exec on a missing table succeeded")
+	}
+	var n int
+	if err := queryRowDB(s, "SELECT COUNT(*) FROM nowhere").Scan(&n); err == nil {
+		t.Error("QueryRow on a missing table succeeded")
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(s *Store) error
+		want error // nil for an error no store error covers
+		code string
+	}{
+		{"duplicate code on create", func(s *Store) error {
+			_, err := s.CreateTask("dup: Again")
+			return err
+		}, ErrConflict, "conflict"},
+		{"duplicate code on rename", func(s *Store) error {
+			id, err := s.CreateTask("Other")
+			if err != nil {
+				return err
+			}
+			return s.UpdateTask(id, "DUP: Other")
+		}, ErrConflict, "conflict"},
+		{"driver constraint", func(s *Store) error {
+			_, err := execDB(s, "INSERT INTO settings (key, value) VALUES ('k', '1'), ('k', '2')")
+			return err
+		}, ErrConflict, "conflict"},
+		{"unknown task", func(s *Store) error {
+			_, err := s.ResolveTask(command{Task: "NOPE"})
+			return err
+		}, ErrNotFound, "not_found"},
+		{"unknown archived task", func(s *Store) error { return s.UnarchiveTask("NOPE") }, ErrNotFound, "not_found"},
+		{"no rows", func(s *Store) error { return classify(sql.ErrNoRows) }, ErrNotFound, "not_found"},
+		{"plain validation", func(s *Store) error {
+			_, err := s.CreateTask("  ")
+			return err
+		}, nil, ""},
+	}
+	for _, tt := range tests {
+		s := newStore(newTestDB(t))
+		if _, err := s.CreateTask("DUP: First"); err != nil {
+			t.Fatal(err)
+		}
+		err := tt.run(s)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		for _, e := range []error{ErrNotFound, ErrConflict, ErrBusy} {
+			if errors.Is(err, e) != (e == tt.want) {
+				t.Errorf("%s: errors.Is(%v, %v) = %v", tt.name, err, e, !(e == tt.want))
+			}
+		}
+		if got := errorCode(err); got != tt.code {
+			t.Errorf("%s: errorCode = %q, want %q", tt.name, got, tt.code)
+		}
+	}
+}
+
+func TestCommandsReportErrorCodes(t *testing.T) {
+	db := newTestDB(t)
+	in := `{"action":"add_task","title":"DUP: First"}
+{"action":"add_task","title":"dup: Again"}
+{"action":"delete_task","task":"NOPE"}
+{"action":"add_task","title":""}
+`
+	var out bytes.Buffer
+	if err := runCommands(db, strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		ok   bool
+		code string
+	}{{true, ""}, {false, "conflict"}, {false, "not_found"}, {false, ""}}
+	dec := json.NewDecoder(&out)
+	for i, w := range want {
+		var res commandResult
+		if err := dec.Decode(&res); err != nil {
+			t.Fatalf("result %d: %v", i, err)
+		}
+		if res.OK != w.ok || res.Code != w.code || (!res.OK && res.Error == "") {
+			t.Errorf("result %d = %+v, want ok %v code %q", i, res, w.ok, w.code)
+		}
+	}
+}
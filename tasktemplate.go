@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checklistFile is the JSON form of a shared checklist:
+// {"title": "Release", "items": ["Tag", "Build", "Announce"]}.
+type checklistFile struct {
+	Title string   `json:"title"`
+	Items []string `json:"items"`
+}
+
+// parseChecklistFile reads a checklist from JSON (.json) or Markdown, where
+// every checkbox in the file becomes an item and the first heading is the
+// title. Checked boxes are ignored, since a template starts fresh.
+func parseChecklistFile(path string, data []byte) (checklistFile, error) {
+	var c checklistFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return c, fmt.Errorf("%s: invalid checklist JSON: %w", path, err)
+		}
+	} else {
+		sections, err := parseMarkdown(data)
+		if err != nil {
+			return c, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, s := range sections {
+			if c.Title == "" {
+				c.Title = s.title
+			}
+			for _, it := range s.items {
+				c.Items = append(c.Items, it.text)
+			}
+		}
+	}
+	items := []string{}
+	for _, text := range c.Items {
+		if text = strings.TrimSpace(text); text != "" {
+			items = append(items, text)
+		}
+	}
+	if len(items) == 0 {
+		return c, fmt.Errorf("%s: no checklist items found", path)
+	}
+	c.Items = items
+	if c.Title == "" {
+		c.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return c, nil
+}
+
+// loadChecklistFile creates a task from the checklist at path. A non-empty
+// title overrides the one in the file.
+func loadChecklistFile(db *sql.DB, path, title string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	c, err := parseChecklistFile(path, data)
+	if err != nil {
+		return 0, err
+	}
+	if title != "" {
+		c.Title = title
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	taskID, err := createTask(tx, c.Title)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	now := time.Now()
+	for _, text := range c.Items {
+		if saveItem(tx, item{TaskID: taskID, Text: text, Status: NotStarted, CreatedAt: now}) == 0 {
+			tx.Rollback()
+			return 0, fmt.Errorf("could not save %q", text)
+		}
+	}
+	updateTaskStatus(tx, taskID)
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return taskID, nil
+}
+
+func (m *model) loadChecklist(arg string) {
+	path, title, _ := strings.Cut(arg, " ")
+	if path == "" {
+		m.setStatus("Usage: \\load <file.json|file.md> [title]")
+		return
+	}
+	taskID, err := m.store.LoadChecklistFile(path, strings.TrimSpace(title))
+	if err != nil {
+		m.setStatus("Load failed: " + err.Error())
+		return
+	}
+	m.reloadTasks()
+	m.openTask(taskID)
+	if t, ok := m.openedTask(); ok {
+		m.setStatus(fmt.Sprintf("Created %s from %s", t.Code, filepath.Base(path)))
+	}
+}
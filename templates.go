@@ -0,0 +1,96 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const templatePrefix = "template."
+
+var placeholderRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// placeholders lists the distinct placeholder names in t, in order of
+// first use.
+func placeholders(t string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, m := range placeholderRe.FindAllStringSubmatch(t, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+func fillTemplate(t string, values map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(t, func(p string) string {
+		if v, ok := values[p[1:len(p)-1]]; ok {
+			return v
+		}
+		return p
+	})
+}
+
+func loadTemplates(db querier) map[string]string {
+	templates := map[string]string{}
+	rows, err := queryDB(db, "SELECT key, value FROM settings WHERE key LIKE ?", templatePrefix+"%")
+	if err != nil {
+		return templates
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		rows.Scan(&key, &value)
+		templates[strings.TrimPrefix(key, templatePrefix)] = value
+	}
+	return templates
+}
+
+// runTemplate handles \tpl: "name text" saves a template, "name" adds an
+// item from it after prompting for each placeholder, and no argument lists
+// the saved names.
+func (m *model) runTemplate(arg string) {
+	name, text, _ := strings.Cut(arg, " ")
+	text = strings.TrimSpace(text)
+	switch {
+	case name == "":
+		names := []string{}
+		for n := range m.store.Templates() {
+			names = append(names, n)
+		}
+		if len(names) == 0 {
+			m.setStatus("No templates, save one with \\tpl <name> <text>")
+			return
+		}
+		sort.Strings(names)
+		m.setStatus("Templates: " + strings.Join(names, ", "))
+	case text != "":
+		m.store.SetSetting(templatePrefix+name, text)
+		m.setStatus("Saved template " + name)
+	default:
+		t, ok := m.store.Templates()[name]
+		if !ok {
+			m.setStatus("No template named " + name)
+			return
+		}
+		if m.selectedTaskID == 0 || m.taskGone() {
+			m.setStatus("Open a task to add an item from a template")
+			return
+		}
+		m.setStatus("")
+		m.promptPlaceholders(t, placeholders(t), map[string]string{})
+	}
+}
+
+func (m *model) promptPlaceholders(t string, names []string, values map[string]string) {
+	if len(names) == 0 {
+		m.addItem(fillTemplate(t, values))
+		return
+	}
+	m.startPrompt(names[0], "", func(m *model, value string, _ int) {
+		values[names[0]] = strings.TrimSpace(value)
+		m.promptPlaceholders(t, names[1:], values)
+	})
+}
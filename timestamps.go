@@ -0,0 +1,19 @@
+package main
+
+import "time"
+
+// Timestamps are stored as UTC RFC 3339 strings and converted to local time
+// when loaded, so day boundaries are always computed in the viewer's zone no
+// matter which zone the row was written in.
+
+func formatStamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseStamp(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Local(), nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// inZone runs the test with time.Local set to zone.
+func inZone(t *testing.T, zone *time.Location) {
+	t.Helper()
+	prev := time.Local
+	time.Local = zone
+	t.Cleanup(func() { time.Local = prev })
+}
+
+func TestStampsRoundTripThroughUTC(t *testing.T) {
+	inZone(t, time.FixedZone("UTC-5", -5*3600))
+	tests := []struct {
+		name   string
+		stored string
+		want   time.Time
+	}{
+		{"utc row", "2026-03-03T03:30:00Z", time.Date(2026, 3, 2, 22, 30, 0, 0, time.Local)},
+		{"legacy local-offset row", "2026-03-02T22:30:00-05:00", time.Date(2026, 3, 2, 22, 30, 0, 0, time.Local)},
+		{"row written in another zone", "2026-03-03T04:30:00+01:00", time.Date(2026, 3, 2, 22, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseStamp(tt.stored)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !got.Equal(tt.want) || got.Location() != time.Local {
+			t.Errorf("%s: parseStamp = %v, want %v in local time", tt.name, got, tt.want)
+		}
+		if s := formatStamp(got); s != "2026-03-03T03:30:00Z" {
+			t.Errorf("%s: formatStamp = %q, want UTC", tt.name, s)
+		}
+	}
+}
+
+func TestDayBoundaryInLocalZone(t *testing.T) {
+	inZone(t, time.FixedZone("UTC-5", -5*3600))
+	db := newTestDB(t)
+	id, _ := createTask(db, "LATE: Late night")
+	// 23:30 local is already the next day in UTC.
+	late := time.Date(2026, 3, 2, 23, 30, 0, 0, time.Local)
+	early := time.Date(2026, 3, 3, 0, 30, 0, 0, time.Local)
+	for _, at := range []time.Time{late, early} {
+		saveItem(db, item{TaskID: id, Text: "x", Status: Done, CheckedAt: &at, FrozenDuration: time.Hour})
+	}
+	items, err := queryItemsWithTask(db, "AND i.status = ?", Done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dayTotal{{"2026-03-02", "LATE", 3600}, {"2026-03-03", "LATE", 3600}}
+	got := dailyTotals(items)
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("dailyTotals = %+v, want %+v", got, want)
+	}
+	r, err := buildReport(db, "2026-03-02", "2026-03-02", early)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.totals) != 1 || r.totals[0].Items != 1 {
+		t.Errorf("report for 2026-03-02 = %+v, want just the 23:30 item", r.totals)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type trashEntry struct {
+	TaskID    int64
+	ItemID    int64
+	Label     string
+	DeletedAt string
+}
+
+type trashView struct {
+	entries []trashEntry
+	cursor  int
+}
+
+func loadTrash(db querier) []trashEntry {
+	entries := []trashEntry{}
+	rows, err := queryDB(db, `
+		SELECT t.id, 0, t.code || ' - ' || t.title, t.deleted_at FROM tasks t WHERE t.deleted_at != ''
+		UNION ALL
+		SELECT i.task_id, i.id, i.text || ' (in ' || t.code || ')', i.deleted_at
+			FROM items i JOIN tasks t ON t.id = i.task_id
+			WHERE i.deleted_at != '' AND t.deleted_at = ''
+		ORDER BY 4 DESC`)
+	if err != nil {
+		return entries
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e trashEntry
+		rows.Scan(&e.TaskID, &e.ItemID, &e.Label, &e.DeletedAt)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func restoreEntry(db querier, e trashEntry) error {
+	if e.ItemID != 0 {
+		_, err := execDB(db, "UPDATE items SET deleted_at = '' WHERE id = ?", e.ItemID)
+		return err
+	}
+	_, err := execDB(db, "UPDATE tasks SET deleted_at = '' WHERE id = ?", e.TaskID)
+	return err
+}
+
+func purgeEntry(db querier, e trashEntry) error {
+	if e.ItemID != 0 {
+		_, err := execDB(db, "DELETE FROM items WHERE id = ?", e.ItemID)
+		return err
+	}
+	_, err := execDB(db, "DELETE FROM tasks WHERE id = ?", e.TaskID)
+	return err
+}
+
+func (m *model) openTrash() {
+	m.trash = &trashView{entries: m.store.Trash()}
+	m.input.Placeholder = `\restore or \purge the selected entry`
+}
+
+func (m model) updateTrash(msg tea.KeyMsg, input string) (model, tea.Cmd) {
+	t := m.trash
+	switch msg.String() {
+	case "ctrl+c":
+		return m, m.quit()
+
+	case "esc":
+		m.trash = nil
+		m.input.Placeholder = m.placeholder()
+		m.input.SetValue("")
+		if m.selectedTaskID != 0 && !m.taskGone() {
+			m.reloadItems()
+		} else {
+			m.reloadTasks()
+		}
+		return m, nil
+
+	case "up":
+		if t.cursor > 0 {
+			t.cursor--
+		}
+		return m, nil
+
+	case "down":
+		if t.cursor < len(t.entries)-1 {
+			t.cursor++
+		}
+		return m, nil
+
+	case "enter":
+		m.input.SetValue("")
+		if t.cursor >= len(t.entries) {
+			return m, nil
+		}
+		e := t.entries[t.cursor]
+		var err error
+		switch input {
+		case `\restore`:
+			err = m.store.RestoreEntry(e)
+		case `\purge`:
+			err = m.store.PurgeEntry(e)
+		case `\q`:
+			return m, m.quit()
+		default:
+			m.setStatus(`Use \restore or \purge, esc to leave the trash`)
+			return m, nil
+		}
+		if err != nil {
+			m.setStatus("Trash: " + err.Error())
+			return m, nil
+		}
+		m.store.UpdateTaskStatus(e.TaskID)
+		t.entries = m.store.Trash()
+		t.cursor = max(min(t.cursor, len(t.entries)-1), 0)
+		m.setStatus(strings.TrimPrefix(input, `\`) + "d " + e.Label)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewTrash(b *strings.Builder) {
+	t := m.trash
+	b.WriteString("Trash\n\n")
+	if len(t.entries) == 0 {
+		b.WriteString(m.emptyState("The trash is empty"))
+	}
+	for i, e := range t.entries {
+		cursor := " "
+		if i == t.cursor {
+			cursor = ">"
+		}
+		kind := "task"
+		if e.ItemID != 0 {
+			kind = "item"
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s\n", cursor, kind, e.Label))
+	}
+	b.WriteString("\n" + m.inputView())
+	b.WriteString(m.statusLine())
+	b.WriteString("\n\n↑/↓ to move • \\restore to restore • \\purge to delete permanently • esc to go back")
+}